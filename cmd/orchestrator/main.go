@@ -1,44 +1,279 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
 
 	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/config"
+	"saga-order-system/internal/health"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/metrics"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/openapi"
 	"saga-order-system/internal/orchestrator"
+	"saga-order-system/internal/server"
+	"saga-order-system/internal/tracing"
 )
 
 func main() {
-	r := gin.Default()
+	logger := logging.New(os.Stdout, "orchestrator")
+	logging.SetDefault(logger)
 
-	orch := orchestrator.NewOrchestrator(
-		"http://localhost:8081", // Order Service
-		"http://localhost:8082", // Payment Service
-		"http://localhost:8083", // Shipping Service
-	)
+	cfg, err := config.Load(config.Orchestrator, os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(gin.Recovery(), logging.Middleware(logger))
+	registry := metrics.NewRegistry()
+	r.Use(metrics.Middleware(registry))
+	r.GET("/metrics", registry.Handler())
+
+	exporter, err := tracing.NewExporter(cfg.TraceOutput)
+	if err != nil {
+		log.Fatalf("Failed to open trace output: %v", err)
+	}
+	tracer := tracing.NewTracer("orchestrator", exporter)
+	r.Use(tracing.Middleware(tracer))
+
+	doc := apiDocument()
+	r.Use(openapi.Validate(doc))
+	r.GET("/openapi.json", doc.Handler())
+
+	orch, err := orchestrator.NewOrchestrator(*cfg, registry, tracer)
+	if err != nil {
+		log.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	if err := orch.ValidateDefinitions(); err != nil {
+		log.Fatalf("Invalid saga definition: %v", err)
+	}
+	health.Register(r, cfg.HTTPTimeout.Std(), orch.ReadinessChecks())
+
+	setupRoutes(r, orch)
+
+	if err := doc.Verify(r.Routes()); err != nil {
+		log.Fatalf("OpenAPI document out of date: %v", err)
+	}
 
+	logger.Info("Orchestrator service starting", "addr", cfg.Orchestrator.Addr, "transport", cfg.Transport)
+	// Sagas cut off by the last shutdown continue alongside new ones.
+	go orch.Recover(context.Background())
+
+	err = server.Run(cfg.Orchestrator.Addr, r, cfg.ShutdownTimeout.Std(), orch.Drain)
+	orch.Close()
+	if err != nil {
+		log.Fatalf("Failed to start orchestrator: %v", err)
+	}
+}
+
+// setupRoutes registers the orchestrator's API, as described by
+// apiDocument, on r.
+func setupRoutes(r *gin.Engine, orch *orchestrator.Orchestrator) {
 	r.POST("/create-order-saga", func(c *gin.Context) {
 		var req orchestrator.CreateOrderRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apierror.Respond(c, apierror.InvalidRequest(err.Error()))
 			return
 		}
 
-		orderID, err := orch.CreateOrderSaga(req)
+		saga, err := orch.CreateOrderSaga(c.Request.Context(), req)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apierror.Respond(c, sagaError(saga, err))
+			return
+		}
+
+		if saga.Status == orchestrator.SagaStatusAwaitingPaymentReview {
+			c.JSON(http.StatusAccepted, gin.H{
+				"message":  "Order saga is waiting for payment review",
+				"saga_id":  saga.ID,
+				"order_id": saga.OrderID,
+			})
 			return
 		}
 
 		c.JSON(http.StatusCreated, gin.H{
 			"message":  "Order saga completed successfully",
-			"order_id": orderID,
+			"saga_id":  saga.ID,
+			"order_id": saga.OrderID,
 		})
 	})
 
-	log.Println("Orchestrator service starting on :8080")
-	if err := r.Run(":8080"); err != nil {
-		log.Fatalf("Failed to start orchestrator: %v", err)
+	r.GET("/sagas/:id", func(c *gin.Context) {
+		saga, exists := orch.GetSaga(c.Param("id"))
+		if !exists {
+			apierror.Respond(c, apierror.NotFound("Saga not found"))
+			return
+		}
+
+		c.JSON(http.StatusOK, saga.Response())
+	})
+
+	r.POST("/sagas/:id/retry", func(c *gin.Context) {
+		saga, err := orch.RetrySaga(c.Request.Context(), c.Param("id"))
+		switch {
+		case errors.Is(err, orchestrator.ErrSagaNotFound):
+			apierror.Respond(c, apierror.NotFound(err.Error()))
+			return
+		case errors.Is(err, orchestrator.ErrSagaNotResumable):
+			apierror.Respond(c, apierror.Conflict(err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusOK, saga.Response())
+	})
+
+	// Called by the payment service once a payment held for fraud review
+	// has been approved or rejected. The notification only says that the
+	// review is over; the saga reads the outcome from the payment itself.
+	r.POST("/sagas/:id/payment-review", func(c *gin.Context) {
+		saga, err := orch.ResumePaymentReview(c.Request.Context(), c.Param("id"))
+		switch {
+		case errors.Is(err, orchestrator.ErrSagaNotFound):
+			apierror.Respond(c, apierror.NotFound(err.Error()))
+			return
+		case errors.Is(err, orchestrator.ErrSagaNotResumable):
+			apierror.Respond(c, apierror.Conflict(err.Error()))
+			return
+		case saga == nil && err != nil:
+			apierror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, saga.Response())
+	})
+
+	r.POST("/orders/:id/cancel", func(c *gin.Context) {
+		saga, err := orch.CancelOrderSaga(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			apierror.Respond(c, sagaError(saga, err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Order cancelled successfully",
+			"saga_id":  saga.ID,
+			"order_id": saga.OrderID,
+		})
+	})
+
+	r.POST("/orders/:id/modify", func(c *gin.Context) {
+		var req orchestrator.ModifyOrderRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+			return
+		}
+
+		saga, err := orch.ModifyOrderSaga(c.Request.Context(), c.Param("id"), req)
+		if err != nil {
+			apierror.Respond(c, sagaError(saga, err))
+			return
+		}
+
+		if saga.Status == orchestrator.SagaStatusAwaitingPaymentReview {
+			c.JSON(http.StatusAccepted, gin.H{
+				"message":     "Order modification is waiting for payment review",
+				"saga_id":     saga.ID,
+				"order_id":    saga.OrderID,
+				"total_price": saga.Amount,
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "Order modified successfully",
+			"saga_id":     saga.ID,
+			"order_id":    saga.OrderID,
+			"total_price": saga.Amount,
+		})
+	})
+
+	r.POST("/returns", func(c *gin.Context) {
+		var req models.CreateReturnRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+			return
+		}
+
+		saga, err := orch.ReturnSaga(c.Request.Context(), req)
+		if err != nil {
+			apierror.Respond(c, sagaError(saga, err))
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":     "Return opened, waiting for goods to be received",
+			"saga_id":     saga.ID,
+			"return_id":   saga.Return.ID,
+			"shipping_id": saga.ShippingID,
+		})
+	})
+
+	// Called by the shipping service once a return shipment has been
+	// delivered to the warehouse.
+	r.POST("/sagas/:id/return-received", func(c *gin.Context) {
+		saga, err := orch.ResumeReturnReceipt(c.Request.Context(), c.Param("id"))
+		switch {
+		case errors.Is(err, orchestrator.ErrSagaNotFound):
+			apierror.Respond(c, apierror.NotFound(err.Error()))
+			return
+		case errors.Is(err, orchestrator.ErrSagaNotResumable):
+			apierror.Respond(c, apierror.Conflict(err.Error()))
+			return
+		case saga == nil && err != nil:
+			apierror.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, saga.Response())
+	})
+}
+
+// sagaError describes the error a saga ended with to the client. When a
+// service's error caused it, that error is passed on as the cause, and a
+// client error from the service, such as a declined payment, also gives the
+// response its code.
+func sagaError(saga *orchestrator.Saga, err error) *apierror.Error {
+	var cause *apierror.Error
+	errors.As(err, &cause)
+
+	status, code := http.StatusInternalServerError, apierror.CodeSagaFailed
+	switch {
+	case errors.Is(err, orchestrator.ErrShuttingDown):
+		status, code = http.StatusServiceUnavailable, apierror.CodeUnavailable
+	case errors.Is(err, orchestrator.ErrOrderLocked):
+		status, code = http.StatusConflict, apierror.CodeLocked
+	case errors.Is(err, orchestrator.ErrOrderNotCancellable),
+		errors.Is(err, orchestrator.ErrOrderNotModifiable),
+		errors.Is(err, orchestrator.ErrOrderAlreadyShipped):
+		status, code = http.StatusConflict, apierror.CodeConflict
+	case cause != nil && cause.Status < http.StatusInternalServerError:
+		status, code = http.StatusUnprocessableEntity, cause.Code
+		// These mean the same to the client as they did to the saga; a
+		// bad or missing resource downstream is a bad saga request.
+		switch cause.Status {
+		case http.StatusPaymentRequired, http.StatusForbidden, http.StatusConflict:
+			status = cause.Status
+		}
+	}
+
+	sagaErr := apierror.New(status, code, err.Error()).
+		WithDetail("saga_id", saga.ID).
+		WithDetail("saga_status", saga.Status)
+	if saga.FailedStep != "" {
+		sagaErr.WithDetail("failed_step", saga.FailedStep)
+	}
+	if cause != nil {
+		sagaErr.WithDetail("cause", cause)
+		sagaErr.Retryable = cause.Retryable
+	}
+	if errors.Is(err, orchestrator.ErrShuttingDown) || errors.Is(err, orchestrator.ErrOrderLocked) {
+		sagaErr.Retryable = true
 	}
-}
\ No newline at end of file
+	return sagaErr
+}
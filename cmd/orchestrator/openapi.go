@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/openapi"
+	"saga-order-system/internal/orchestrator"
+)
+
+type sagaResult struct {
+	Message string `json:"message"`
+	SagaID  string `json:"saga_id"`
+	OrderID string `json:"order_id"`
+}
+
+type modifyResult struct {
+	sagaResult
+	TotalPrice float64 `json:"total_price"`
+}
+
+type returnResult struct {
+	Message    string `json:"message"`
+	SagaID     string `json:"saga_id"`
+	ReturnID   string `json:"return_id"`
+	ShippingID string `json:"shipping_id"`
+}
+
+// apiDocument describes the orchestrator's routes.
+func apiDocument() *openapi.Document {
+	doc := openapi.New("Order saga orchestrator", "1.0.0")
+
+	doc.Add(http.MethodPost, "/create-order-saga", openapi.Op{
+		Summary: "Place an order; 202 means the saga waits for a payment review",
+		Request: orchestrator.CreateOrderRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:  sagaResult{},
+			http.StatusAccepted: sagaResult{},
+		},
+	})
+	doc.Add(http.MethodPost, "/orders/:id/cancel", openapi.Op{
+		Summary:   "Cancel an order that has not been picked up",
+		Responses: map[int]interface{}{http.StatusOK: sagaResult{}},
+	})
+	doc.Add(http.MethodPost, "/orders/:id/modify", openapi.Op{
+		Summary: "Change the items and/or address of an order that has not been picked up; 202 means the saga waits for a payment review",
+		Request: orchestrator.ModifyOrderRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:       modifyResult{},
+			http.StatusAccepted: modifyResult{},
+		},
+	})
+	doc.Add(http.MethodPost, "/returns", openapi.Op{
+		Summary:   "Return part of a delivered order",
+		Request:   models.CreateReturnRequest{},
+		Responses: map[int]interface{}{http.StatusAccepted: returnResult{}},
+	})
+
+	doc.Add(http.MethodGet, "/sagas/:id", openapi.Op{
+		Summary:   "Get a saga",
+		Responses: map[int]interface{}{http.StatusOK: orchestrator.SagaResponse{}},
+	})
+	doc.Add(http.MethodPost, "/sagas/:id/retry", openapi.Op{
+		Summary:   "Continue a stalled saga",
+		Responses: map[int]interface{}{http.StatusOK: orchestrator.SagaResponse{}},
+	})
+	doc.Add(http.MethodPost, "/sagas/:id/payment-review", openapi.Op{
+		Summary:   "Continue a saga with the outcome of its payment review",
+		Request:   models.PaymentReviewNotification{},
+		Responses: map[int]interface{}{http.StatusOK: orchestrator.SagaResponse{}},
+	})
+	doc.Add(http.MethodPost, "/sagas/:id/return-received", openapi.Op{
+		Summary:   "Continue a return saga once the goods reach the warehouse",
+		Responses: map[int]interface{}{http.StatusOK: orchestrator.SagaResponse{}},
+	})
+
+	return doc
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPIDocument(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// The handlers only use the orchestrator when called.
+	setupRoutes(r, nil)
+
+	if err := apiDocument().Verify(r.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,20 +1,74 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"saga-order-system/internal/config"
+	"saga-order-system/internal/health"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/metrics"
+	"saga-order-system/internal/openapi"
+	"saga-order-system/internal/rpc"
+	"saga-order-system/internal/rpc/pb"
+	"saga-order-system/internal/server"
 	"saga-order-system/internal/services/order"
+	"saga-order-system/internal/tracing"
 )
 
 func main() {
-	r := gin.Default()
+	logger := logging.New(os.Stdout, "order-service")
+	logging.SetDefault(logger)
+
+	cfg, err := config.Load(config.Order, os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(gin.Recovery(), logging.Middleware(logger))
+	registry := metrics.NewRegistry()
+	r.Use(metrics.Middleware(registry))
+	r.GET("/metrics", registry.Handler())
+
+	exporter, err := tracing.NewExporter(cfg.TraceOutput)
+	if err != nil {
+		log.Fatalf("Failed to open trace output: %v", err)
+	}
+	tracer := tracing.NewTracer("order-service", exporter)
+	r.Use(tracing.Middleware(tracer))
+
+	doc := order.OpenAPI()
+	r.Use(openapi.Validate(doc))
+	r.GET("/openapi.json", doc.Handler())
 
 	service := order.NewService()
 	service.SetupRoutes(r)
+	health.Register(r, cfg.HTTPTimeout.Std(), map[string]health.Check{
+		"storage": service.Ready,
+	})
+
+	if err := doc.Verify(r.Routes()); err != nil {
+		log.Fatalf("OpenAPI document out of date: %v", err)
+	}
+
+	// The gRPC server is stopped as the HTTP server shuts down.
+	var stopGRPC func(ctx context.Context) error
+	if cfg.Order.GRPCAddr != "" {
+		grpcServer := grpc.NewServer(grpc.UnaryInterceptor(rpc.ServerInterceptor(logger, tracer, registry)))
+		pb.RegisterOrderServiceServer(grpcServer, rpc.NewOrderServer(service))
+		stopGRPC, err = rpc.Serve(cfg.Order.GRPCAddr, grpcServer)
+		if err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+		logger.Info("Order service serving gRPC", "addr", cfg.Order.GRPCAddr)
+	}
 
-	log.Println("Order service starting on :8081")
-	if err := r.Run(":8081"); err != nil {
+	logger.Info("Order service starting", "addr", cfg.Order.Addr)
+	if err := server.Run(cfg.Order.Addr, r, cfg.ShutdownTimeout.Std(), stopGRPC); err != nil {
 		log.Fatalf("Failed to start order service: %v", err)
 	}
-}
\ No newline at end of file
+}
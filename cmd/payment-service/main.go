@@ -1,20 +1,85 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"saga-order-system/internal/config"
+	"saga-order-system/internal/health"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/metrics"
+	"saga-order-system/internal/openapi"
+	"saga-order-system/internal/rpc"
+	"saga-order-system/internal/rpc/pb"
+	"saga-order-system/internal/server"
 	"saga-order-system/internal/services/payment"
+	"saga-order-system/internal/tracing"
 )
 
 func main() {
-	r := gin.Default()
+	logger := logging.New(os.Stdout, "payment-service")
+	logging.SetDefault(logger)
 
-	service := payment.NewService()
+	cfg, err := config.Load(config.Payment, os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(gin.Recovery(), logging.Middleware(logger))
+	registry := metrics.NewRegistry()
+	r.Use(metrics.Middleware(registry))
+	r.GET("/metrics", registry.Handler())
+
+	exporter, err := tracing.NewExporter(cfg.TraceOutput)
+	if err != nil {
+		log.Fatalf("Failed to open trace output: %v", err)
+	}
+	tracer := tracing.NewTracer("payment-service", exporter)
+	r.Use(tracing.Middleware(tracer))
+
+	doc := payment.OpenAPI()
+	r.Use(openapi.Validate(doc))
+	r.GET("/openapi.json", doc.Handler())
+
+	var blocklist *payment.Blocklist
+	if cfg.FraudBlocklistFile != "" {
+		if blocklist, err = payment.LoadBlocklist(cfg.FraudBlocklistFile); err != nil {
+			log.Fatalf("Failed to load fraud blocklist: %v", err)
+		}
+	}
+
+	service := payment.NewService(
+		payment.NewFakeGateway(),
+		payment.NewFraudScreener(payment.DefaultFraudRules(), blocklist),
+		cfg.HTTPTimeout.Std(),
+	)
 	service.SetupRoutes(r)
+	health.Register(r, cfg.HTTPTimeout.Std(), map[string]health.Check{
+		"storage": service.Ready,
+	})
+
+	if err := doc.Verify(r.Routes()); err != nil {
+		log.Fatalf("OpenAPI document out of date: %v", err)
+	}
+
+	// The gRPC server is stopped as the HTTP server shuts down.
+	var stopGRPC func(ctx context.Context) error
+	if cfg.Payment.GRPCAddr != "" {
+		grpcServer := grpc.NewServer(grpc.UnaryInterceptor(rpc.ServerInterceptor(logger, tracer, registry)))
+		pb.RegisterPaymentServiceServer(grpcServer, rpc.NewPaymentServer(service))
+		stopGRPC, err = rpc.Serve(cfg.Payment.GRPCAddr, grpcServer)
+		if err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+		logger.Info("Payment service serving gRPC", "addr", cfg.Payment.GRPCAddr)
+	}
 
-	log.Println("Payment service starting on :8082")
-	if err := r.Run(":8082"); err != nil {
+	logger.Info("Payment service starting", "addr", cfg.Payment.Addr)
+	if err := server.Run(cfg.Payment.Addr, r, cfg.ShutdownTimeout.Std(), stopGRPC); err != nil {
 		log.Fatalf("Failed to start payment service: %v", err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,161 @@
+// Package apierror defines the error body every service responds with and
+// the typed errors behind it, so that callers can tell why a request failed
+// and whether making it again may succeed.
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Code says why a request failed, more precisely than its status code.
+type Code string
+
+const (
+	CodeInvalidRequest     Code = "INVALID_REQUEST"
+	CodeValidationFailed   Code = "VALIDATION_FAILED"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeConflict           Code = "CONFLICT"
+	CodeLocked             Code = "LOCKED"
+	CodePreconditionFailed Code = "PRECONDITION_FAILED"
+	CodeInsufficientStock  Code = "INSUFFICIENT_STOCK"
+	CodePaymentDeclined    Code = "PAYMENT_DECLINED"
+	CodePaymentRejected    Code = "PAYMENT_REJECTED"
+	CodeNotImplemented     Code = "NOT_IMPLEMENTED"
+	CodeUpstreamFailed     Code = "UPSTREAM_FAILED"
+	CodeUpstreamTimeout    Code = "UPSTREAM_TIMEOUT"
+	CodeUnavailable        Code = "UNAVAILABLE"
+	CodeSagaFailed         Code = "SAGA_FAILED"
+	CodeInternal           Code = "INTERNAL"
+)
+
+// Error is a failed request as the client sees it. Retryable tells the
+// client that the same request may succeed later without any change.
+type Error struct {
+	Status    int                    `json:"-"`
+	Code      Code                   `json:"code"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Retryable bool                   `json:"retryable"`
+}
+
+// Response is the body of every error response.
+type Response struct {
+	Error *Error `json:"error"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%s)", e.Message, e.Code)
+}
+
+// New creates an error responded with status. Rate limits and unavailable
+// or timed out services are retryable unless set otherwise. Locked resources
+// are not: a lock is held for as long as its saga runs, which outlasts any
+// backoff.
+func New(status int, code Code, message string) *Error {
+	return &Error{
+		Status:    status,
+		Code:      code,
+		Message:   message,
+		Retryable: retryableStatus(status),
+	}
+}
+
+func InvalidRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeInvalidRequest, message)
+}
+
+func ValidationFailed(message string) *Error {
+	return New(http.StatusUnprocessableEntity, CodeValidationFailed, message)
+}
+
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+func Internal(message string) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, message)
+}
+
+// WithDetail adds a detail to the error and returns it.
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// Respond writes err as the response. Errors that are not an *Error are
+// internal errors.
+func Respond(c *gin.Context, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = Internal(err.Error())
+	}
+	c.JSON(apiErr.Status, Response{Error: apiErr})
+}
+
+// FromResponse reads the error a service answered with. A body that is not
+// an error response still gives an error, with a code and retryability
+// derived from the status.
+func FromResponse(resp *http.Response) *Error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var decoded Response
+	if err := json.Unmarshal(body, &decoded); err == nil && decoded.Error != nil && decoded.Error.Code != "" {
+		decoded.Error.Status = resp.StatusCode
+		return decoded.Error
+	}
+
+	return New(resp.StatusCode, codeForStatus(resp.StatusCode), fmt.Sprintf("status code %d", resp.StatusCode))
+}
+
+// HasStatus reports whether err carries an *Error with the status.
+func HasStatus(err error, status int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Status == status
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func codeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusLocked:
+		return CodeLocked
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	case http.StatusBadGateway:
+		return CodeUpstreamFailed
+	case http.StatusGatewayTimeout:
+		return CodeUpstreamTimeout
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	return CodeInternal
+}
@@ -0,0 +1,131 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewRetryable(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusNotFound, false},
+		{http.StatusConflict, false},
+		{http.StatusLocked, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, false},
+		{http.StatusBadGateway, false},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			if got := New(tt.status, CodeInternal, "failed").Retryable; got != tt.want {
+				t.Fatalf("Retryable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		wantCode      Code
+		wantMessage   string
+		wantRetryable bool
+	}{
+		{
+			name:        "error envelope",
+			status:      http.StatusConflict,
+			body:        `{"error":{"code":"INSUFFICIENT_STOCK","message":"p1 is out of stock","retryable":false}}`,
+			wantCode:    CodeInsufficientStock,
+			wantMessage: "p1 is out of stock",
+		},
+		{
+			name:          "envelope overriding retryability",
+			status:        http.StatusInternalServerError,
+			body:          `{"error":{"code":"INTERNAL","message":"try again","retryable":true}}`,
+			wantCode:      CodeInternal,
+			wantMessage:   "try again",
+			wantRetryable: true,
+		},
+		{
+			name:          "plain text from a proxy",
+			status:        http.StatusServiceUnavailable,
+			body:          "upstream connect error",
+			wantCode:      CodeUnavailable,
+			wantMessage:   "status code 503",
+			wantRetryable: true,
+		},
+		{
+			name:        "JSON without an error",
+			status:      http.StatusLocked,
+			body:        `{"message":"locked"}`,
+			wantCode:    CodeLocked,
+			wantMessage: "status code 423",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader(tt.body))}
+			err := FromResponse(resp)
+			if err.Status != tt.status || err.Code != tt.wantCode || err.Message != tt.wantMessage || err.Retryable != tt.wantRetryable {
+				t.Fatalf("FromResponse() = %+v", err)
+			}
+		})
+	}
+}
+
+func TestRespond(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   Code
+	}{
+		{
+			name:       "wrapped API error",
+			err:        fmt.Errorf("reserve stock: %w", NotFound("Order o1 not found")),
+			wantStatus: http.StatusNotFound,
+			wantCode:   CodeNotFound,
+		},
+		{
+			name:       "plain error",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			Respond(c, tt.err)
+
+			var resp Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.wantStatus || resp.Error == nil || resp.Error.Code != tt.wantCode {
+				t.Fatalf("responded %d %s", w.Code, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Package clients are typed HTTP clients for the order, payment and
+// shipping services, speaking the shared models. Every call passes on the
+// request and saga IDs and the trace carried by its context, and a service's
+// error response comes back as an error wrapping its *apierror.Error.
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/tracing"
+)
+
+// client makes the JSON requests shared by the service clients.
+type client struct {
+	service    string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func newClient(service, baseURL string, httpClient *http.Client) client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return client{
+		service:    service,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// do sends body, if any, as JSON to path and decodes the response into out
+// when it is not nil. A status outside want is an error.
+func (c client) do(ctx context.Context, method, path string, body, out interface{}, want ...int) error {
+	var reqBody io.Reader
+	if body != nil {
+		reqJSON, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewBuffer(reqJSON)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	logging.Propagate(ctx, req.Header)
+	tracing.Propagate(ctx, req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !expected(resp.StatusCode, want) {
+		return fmt.Errorf("%s service %s %s: %w", c.service, method, path, apierror.FromResponse(resp))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func expected(status int, want []int) bool {
+	for _, w := range want {
+		if status == w {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/tracing"
+)
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		wantStatus    int
+		wantCode      apierror.Code
+		wantRetryable bool
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"id": "o1", "status": "PENDING"}`,
+		},
+		{
+			name:       "error envelope",
+			status:     http.StatusNotFound,
+			body:       `{"error": {"code": "NOT_FOUND", "message": "Order o1 not found"}}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   apierror.CodeNotFound,
+		},
+		{
+			name:       "locked order",
+			status:     http.StatusLocked,
+			body:       `{"error": {"code": "LOCKED", "message": "Order o1 is locked by another saga"}}`,
+			wantStatus: http.StatusLocked,
+			wantCode:   apierror.CodeLocked,
+		},
+		{
+			name:          "unavailable without an envelope",
+			status:        http.StatusServiceUnavailable,
+			body:          `no healthy upstream`,
+			wantStatus:    http.StatusServiceUnavailable,
+			wantCode:      apierror.CodeUnavailable,
+			wantRetryable: true,
+		},
+		{
+			name:       "unexpected success status",
+			status:     http.StatusNoContent,
+			wantStatus: http.StatusNoContent,
+			wantCode:   apierror.CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/orders/o1" {
+					t.Errorf("requested %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			order, err := NewOrderClient(server.URL+"/", nil).GetOrder(context.Background(), "o1")
+			if tt.wantCode == "" {
+				if err != nil || order.ID != "o1" || order.Status != models.OrderStatusPending {
+					t.Fatalf("GetOrder() = %+v, %v", order, err)
+				}
+				return
+			}
+
+			var apiErr *apierror.Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GetOrder() error = %v, want an *apierror.Error", err)
+			}
+			if apiErr.Status != tt.wantStatus || apiErr.Code != tt.wantCode || apiErr.Retryable != tt.wantRetryable {
+				t.Errorf("error %+v, want status %d code %s retryable %v", apiErr, tt.wantStatus, tt.wantCode, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestClientPropagates(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(`{"message": "cancelled"}`))
+	}))
+	defer server.Close()
+
+	ctx := logging.ContextWithIDs(context.Background(), "r1", "s1")
+	ctx, span := tracing.NewTracer("test", nil).Start(ctx, "cancel")
+	if err := NewOrderClient(server.URL, nil).CancelOrder(ctx, "o1"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		logging.RequestIDHeader: "r1",
+		models.SagaIDHeader:     "s1",
+		"Traceparent":           span.Context().Traceparent(),
+		"Content-Type":          "application/json",
+	}
+	for header, value := range want {
+		if got.Get(header) != value {
+			t.Errorf("%s = %q, want %q", header, got.Get(header), value)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+
+	"saga-order-system/internal/models"
+)
+
+// OrderClient calls the order service, which also keeps inventory and
+// returns. Requests made with a saga ID in their context act for that saga
+// on orders it has locked.
+type OrderClient struct {
+	client
+}
+
+func NewOrderClient(baseURL string, httpClient *http.Client) *OrderClient {
+	return &OrderClient{newClient("order", baseURL, httpClient)}
+}
+
+func (c *OrderClient) CreateOrder(ctx context.Context, req models.CreateOrderRequest) (*models.OrderResponse, error) {
+	var order models.OrderResponse
+	if err := c.do(ctx, http.MethodPost, "/create-order", req, &order, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (c *OrderClient) GetOrder(ctx context.Context, orderID string) (*models.OrderResponse, error) {
+	var order models.OrderResponse
+	if err := c.do(ctx, http.MethodGet, "/orders/"+orderID, nil, &order, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (c *OrderClient) CancelOrder(ctx context.Context, orderID string) error {
+	req := models.CancelOrderRequest{OrderID: orderID}
+	return c.do(ctx, http.MethodPost, "/cancel-order", req, nil, http.StatusOK)
+}
+
+func (c *OrderClient) ModifyOrder(ctx context.Context, orderID string, req models.ModifyOrderRequest) (*models.OrderResponse, error) {
+	var order models.OrderResponse
+	if err := c.do(ctx, http.MethodPost, "/orders/"+orderID+"/modify", req, &order, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (c *OrderClient) LockOrder(ctx context.Context, orderID string, req models.LockOrderRequest) (*models.OrderResponse, error) {
+	var order models.OrderResponse
+	if err := c.do(ctx, http.MethodPost, "/orders/"+orderID+"/lock", req, &order, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (c *OrderClient) UnlockOrder(ctx context.Context, orderID string, req models.UnlockOrderRequest) error {
+	return c.do(ctx, http.MethodPost, "/orders/"+orderID+"/unlock", req, nil, http.StatusOK)
+}
+
+func (c *OrderClient) GetStock(ctx context.Context, productID string) (*models.StockLevel, error) {
+	var stock models.StockLevel
+	if err := c.do(ctx, http.MethodGet, "/inventory/"+productID, nil, &stock, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+func (c *OrderClient) Restock(ctx context.Context, req models.StockAdjustmentRequest) ([]models.StockLevel, error) {
+	var levels []models.StockLevel
+	if err := c.do(ctx, http.MethodPost, "/inventory/restock", req, &levels, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// DeductStock takes units off hand, failing without changes when any
+// product does not have enough stock.
+func (c *OrderClient) DeductStock(ctx context.Context, req models.StockAdjustmentRequest) ([]models.StockLevel, error) {
+	var levels []models.StockLevel
+	if err := c.do(ctx, http.MethodPost, "/inventory/deduct", req, &levels, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// ReserveStock holds stock for an order. Reserving again for the same order
+// returns the existing reservation.
+func (c *OrderClient) ReserveStock(ctx context.Context, req models.ReserveStockRequest) (*models.InventoryReservation, error) {
+	var reservation models.InventoryReservation
+	if err := c.do(ctx, http.MethodPost, "/inventory/reservations", req, &reservation, http.StatusCreated, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+func (c *OrderClient) ReleaseStock(ctx context.Context, orderID string) error {
+	return c.do(ctx, http.MethodPost, "/inventory/reservations/"+orderID+"/release", nil, nil, http.StatusOK)
+}
+
+func (c *OrderClient) CreateReturn(ctx context.Context, req models.CreateReturnRequest) (*models.Return, error) {
+	var ret models.Return
+	if err := c.do(ctx, http.MethodPost, "/returns", req, &ret, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (c *OrderClient) GetReturn(ctx context.Context, returnID string) (*models.Return, error) {
+	var ret models.Return
+	if err := c.do(ctx, http.MethodGet, "/returns/"+returnID, nil, &ret, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (c *OrderClient) ReceiveReturn(ctx context.Context, returnID string) (*models.Return, error) {
+	return c.moveReturn(ctx, returnID, "receive")
+}
+
+func (c *OrderClient) CompleteReturn(ctx context.Context, returnID string) (*models.Return, error) {
+	return c.moveReturn(ctx, returnID, "complete")
+}
+
+func (c *OrderClient) CancelReturn(ctx context.Context, returnID string) error {
+	return c.do(ctx, http.MethodPost, "/returns/"+returnID+"/cancel", nil, nil, http.StatusOK)
+}
+
+func (c *OrderClient) moveReturn(ctx context.Context, returnID, action string) (*models.Return, error) {
+	var ret models.Return
+	if err := c.do(ctx, http.MethodPost, "/returns/"+returnID+"/"+action, nil, &ret, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
@@ -0,0 +1,54 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+
+	"saga-order-system/internal/models"
+)
+
+// PaymentClient calls the payment service.
+type PaymentClient struct {
+	client
+}
+
+func NewPaymentClient(baseURL string, httpClient *http.Client) *PaymentClient {
+	return &PaymentClient{newClient("payment", baseURL, httpClient)}
+}
+
+// ProcessPayment charges for an order. A payment held for fraud review comes
+// back with status PENDING_REVIEW, and the outcome is later posted to the
+// request's CallbackURL.
+func (c *PaymentClient) ProcessPayment(ctx context.Context, req models.ProcessPaymentRequest) (*models.PaymentResponse, error) {
+	var payment models.PaymentResponse
+	if err := c.do(ctx, http.MethodPost, "/process-payment", req, &payment, http.StatusCreated, http.StatusAccepted); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (c *PaymentClient) RefundPayment(ctx context.Context, req models.RefundPaymentRequest) error {
+	return c.do(ctx, http.MethodPost, "/refund-payment", req, nil, http.StatusOK)
+}
+
+// VoidPayment releases the order's payments held for fraud review. An order
+// with none is not an error.
+func (c *PaymentClient) VoidPayment(ctx context.Context, req models.VoidPaymentRequest) error {
+	return c.do(ctx, http.MethodPost, "/void-payment", req, nil, http.StatusOK)
+}
+
+func (c *PaymentClient) GetPayment(ctx context.Context, paymentID string) (*models.PaymentResponse, error) {
+	var payment models.PaymentResponse
+	if err := c.do(ctx, http.MethodGet, "/payments/"+paymentID, nil, &payment, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (c *PaymentClient) ReviewPayment(ctx context.Context, paymentID string, req models.ReviewPaymentRequest) (*models.PaymentResponse, error) {
+	var payment models.PaymentResponse
+	if err := c.do(ctx, http.MethodPost, "/payments/"+paymentID+"/review", req, &payment, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
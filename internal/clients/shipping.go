@@ -0,0 +1,66 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+
+	"saga-order-system/internal/models"
+)
+
+// ShippingClient calls the shipping service.
+type ShippingClient struct {
+	client
+}
+
+func NewShippingClient(baseURL string, httpClient *http.Client) *ShippingClient {
+	return &ShippingClient{newClient("shipping", baseURL, httpClient)}
+}
+
+// ValidateAddress returns the address in its normalized form.
+func (c *ShippingClient) ValidateAddress(ctx context.Context, address models.Address) (*models.Address, error) {
+	var normalized models.Address
+	if err := c.do(ctx, http.MethodPost, "/addresses/validate", address, &normalized, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+func (c *ShippingClient) StartShipping(ctx context.Context, req models.StartShippingRequest) (*models.ShippingResponse, error) {
+	var shipping models.ShippingResponse
+	if err := c.do(ctx, http.MethodPost, "/start-shipping", req, &shipping, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return &shipping, nil
+}
+
+// CancelShipping fails with status 409 Conflict when a shipment has already
+// been picked up.
+func (c *ShippingClient) CancelShipping(ctx context.Context, req models.CancelShippingRequest) error {
+	return c.do(ctx, http.MethodPost, "/cancel-shipping", req, nil, http.StatusOK)
+}
+
+func (c *ShippingClient) GetShipping(ctx context.Context, shippingID string) (*models.ShippingResponse, error) {
+	var shipping models.ShippingResponse
+	if err := c.do(ctx, http.MethodGet, "/shippings/"+shippingID, nil, &shipping, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &shipping, nil
+}
+
+func (c *ShippingClient) ListOrderShippings(ctx context.Context, orderID string) ([]models.ShippingResponse, error) {
+	var shippings []models.ShippingResponse
+	if err := c.do(ctx, http.MethodGet, "/orders/"+orderID+"/shippings", nil, &shippings, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return shippings, nil
+}
+
+// AddTrackingEvent records a carrier scan, moving the shipment to the
+// event's status.
+func (c *ShippingClient) AddTrackingEvent(ctx context.Context, shippingID string, req models.TrackingEventRequest) (*models.ShippingResponse, error) {
+	var shipping models.ShippingResponse
+	if err := c.do(ctx, http.MethodPost, "/shippings/"+shippingID+"/tracking-events", req, &shipping, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &shipping, nil
+}
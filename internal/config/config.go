@@ -0,0 +1,343 @@
+// Package config loads the settings shared by the orchestrator and the
+// order, payment and shipping services. Values come from, in increasing
+// order of precedence: built-in defaults, a YAML or JSON file, environment
+// variables and command-line flags.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Binary names accepted by Load.
+const (
+	Orchestrator = "orchestrator"
+	Order        = "order"
+	Payment      = "payment"
+	Shipping     = "shipping"
+)
+
+// Transports the orchestrator can call the services over.
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// Endpoint is where a binary listens and the base URL the others use to
+// reach it. A service also serves gRPC on GRPCAddr, if set, which the
+// orchestrator dials at GRPCTarget.
+type Endpoint struct {
+	Addr       string `json:"addr" yaml:"addr"`
+	URL        string `json:"url" yaml:"url"`
+	GRPCAddr   string `json:"grpc_addr" yaml:"grpc_addr"`
+	GRPCTarget string `json:"grpc_target" yaml:"grpc_target"`
+}
+
+type Config struct {
+	Orchestrator Endpoint `json:"orchestrator" yaml:"orchestrator"`
+	Order        Endpoint `json:"order" yaml:"order"`
+	Payment      Endpoint `json:"payment" yaml:"payment"`
+	Shipping     Endpoint `json:"shipping" yaml:"shipping"`
+
+	// Transport is how the orchestrator calls the services: "http" or
+	// "grpc".
+	Transport string `json:"transport" yaml:"transport"`
+	// HTTPTimeout bounds every service-to-service request, over either
+	// transport.
+	HTTPTimeout Duration `json:"http_timeout" yaml:"http_timeout"`
+	// RetryAttempts and RetryBackoff bound how hard the orchestrator
+	// retries a retriable saga step.
+	RetryAttempts int      `json:"retry_attempts" yaml:"retry_attempts"`
+	RetryBackoff  Duration `json:"retry_backoff" yaml:"retry_backoff"`
+	// ShutdownTimeout bounds both draining running work and waiting for
+	// in-flight requests when a binary is stopped.
+	ShutdownTimeout Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+
+	FraudBlocklistFile string `json:"fraud_blocklist_file" yaml:"fraud_blocklist_file"`
+	// SagaStoreFile is the JSON file the orchestrator keeps its sagas in,
+	// so that they survive a restart. Empty keeps them in memory only.
+	SagaStoreFile string `json:"saga_store_file" yaml:"saga_store_file"`
+
+	// TraceOutput is where finished spans are written as JSON lines:
+	// "stdout", a file path, or empty to not record them.
+	TraceOutput string `json:"trace_output" yaml:"trace_output"`
+}
+
+// Default is the configuration used when nothing else is given: every
+// binary on localhost on its usual port, the services also serving gRPC,
+// and the orchestrator calling them over HTTP.
+func Default() Config {
+	return Config{
+		Orchestrator:    Endpoint{Addr: ":8080", URL: "http://localhost:8080"},
+		Order:           Endpoint{Addr: ":8081", URL: "http://localhost:8081", GRPCAddr: ":9081", GRPCTarget: "localhost:9081"},
+		Payment:         Endpoint{Addr: ":8082", URL: "http://localhost:8082", GRPCAddr: ":9082", GRPCTarget: "localhost:9082"},
+		Shipping:        Endpoint{Addr: ":8083", URL: "http://localhost:8083", GRPCAddr: ":9083", GRPCTarget: "localhost:9083"},
+		Transport:       TransportHTTP,
+		HTTPTimeout:     Duration(10 * time.Second),
+		RetryAttempts:   3,
+		RetryBackoff:    Duration(200 * time.Millisecond),
+		ShutdownTimeout: Duration(15 * time.Second),
+	}
+}
+
+// Load builds the configuration for the named binary from args (usually
+// os.Args[1:]) and the environment. The file is given by -config or
+// SAGA_CONFIG; -addr and -grpc-addr set the listen addresses of the binary
+// itself.
+func Load(binary string, args []string) (*Config, error) {
+	cfg := Default()
+
+	own, err := cfg.endpoint(binary)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := flag.NewFlagSet(binary, flag.ContinueOnError)
+	path := flags.String("config", os.Getenv("SAGA_CONFIG"), "path to a YAML or JSON config file")
+	addr := flags.String("addr", "", "listen address")
+	grpcAddr := flags.String("grpc-addr", "", "gRPC listen address, empty to not serve gRPC")
+	transport := flags.String("transport", "", "transport to the services: http or grpc")
+	orchestratorURL := flags.String("orchestrator-url", "", "orchestrator base URL")
+	orderURL := flags.String("order-url", "", "order service base URL")
+	paymentURL := flags.String("payment-url", "", "payment service base URL")
+	shippingURL := flags.String("shipping-url", "", "shipping service base URL")
+	httpTimeout := flags.Duration("http-timeout", 0, "timeout of service-to-service requests")
+	retryAttempts := flags.Int("retry-attempts", 0, "attempts at a retriable saga step before it stalls")
+	retryBackoff := flags.Duration("retry-backoff", 0, "backoff before the first retry of a saga step, doubled on each one")
+	shutdownTimeout := flags.Duration("shutdown-timeout", 0, "bound on draining and on waiting for in-flight requests at shutdown")
+	sagaStoreFile := flags.String("saga-store-file", "", "file the orchestrator keeps its sagas in")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	// Only flags given explicitly override the file and environment.
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			own.Addr = *addr
+		case "grpc-addr":
+			own.GRPCAddr = *grpcAddr
+		case "transport":
+			cfg.Transport = *transport
+		case "orchestrator-url":
+			cfg.Orchestrator.URL = *orchestratorURL
+		case "order-url":
+			cfg.Order.URL = *orderURL
+		case "payment-url":
+			cfg.Payment.URL = *paymentURL
+		case "shipping-url":
+			cfg.Shipping.URL = *shippingURL
+		case "http-timeout":
+			cfg.HTTPTimeout = Duration(*httpTimeout)
+		case "retry-attempts":
+			cfg.RetryAttempts = *retryAttempts
+		case "retry-backoff":
+			cfg.RetryBackoff = Duration(*retryBackoff)
+		case "shutdown-timeout":
+			cfg.ShutdownTimeout = Duration(*shutdownTimeout)
+		case "saga-store-file":
+			cfg.SagaStoreFile = *sagaStoreFile
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Config) endpoint(binary string) (*Endpoint, error) {
+	switch binary {
+	case Orchestrator:
+		return &c.Orchestrator, nil
+	case Order:
+		return &c.Order, nil
+	case Payment:
+		return &c.Payment, nil
+	case Shipping:
+		return &c.Shipping, nil
+	default:
+		return nil, fmt.Errorf("unknown binary %q", binary)
+	}
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".json":
+		err = json.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	stringVars := map[string]*string{
+		"SAGA_ORCHESTRATOR_ADDR":    &c.Orchestrator.Addr,
+		"SAGA_ORCHESTRATOR_URL":     &c.Orchestrator.URL,
+		"SAGA_ORDER_ADDR":           &c.Order.Addr,
+		"SAGA_ORDER_URL":            &c.Order.URL,
+		"SAGA_ORDER_GRPC_ADDR":      &c.Order.GRPCAddr,
+		"SAGA_ORDER_GRPC_TARGET":    &c.Order.GRPCTarget,
+		"SAGA_PAYMENT_ADDR":         &c.Payment.Addr,
+		"SAGA_PAYMENT_URL":          &c.Payment.URL,
+		"SAGA_PAYMENT_GRPC_ADDR":    &c.Payment.GRPCAddr,
+		"SAGA_PAYMENT_GRPC_TARGET":  &c.Payment.GRPCTarget,
+		"SAGA_SHIPPING_ADDR":        &c.Shipping.Addr,
+		"SAGA_SHIPPING_URL":         &c.Shipping.URL,
+		"SAGA_SHIPPING_GRPC_ADDR":   &c.Shipping.GRPCAddr,
+		"SAGA_SHIPPING_GRPC_TARGET": &c.Shipping.GRPCTarget,
+		"SAGA_TRANSPORT":            &c.Transport,
+		"SAGA_FRAUD_BLOCKLIST_FILE": &c.FraudBlocklistFile,
+		"SAGA_TRACE_OUTPUT":         &c.TraceOutput,
+		"SAGA_STORE_FILE":           &c.SagaStoreFile,
+	}
+	for name, field := range stringVars {
+		if value, ok := os.LookupEnv(name); ok {
+			*field = value
+		}
+	}
+
+	durationVars := map[string]*Duration{
+		"SAGA_HTTP_TIMEOUT":     &c.HTTPTimeout,
+		"SAGA_RETRY_BACKOFF":    &c.RetryBackoff,
+		"SAGA_SHUTDOWN_TIMEOUT": &c.ShutdownTimeout,
+	}
+	for name, field := range durationVars {
+		if value, ok := os.LookupEnv(name); ok {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			*field = Duration(d)
+		}
+	}
+
+	if value, ok := os.LookupEnv("SAGA_RETRY_ATTEMPTS"); ok {
+		attempts, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("SAGA_RETRY_ATTEMPTS: %w", err)
+		}
+		c.RetryAttempts = attempts
+	}
+
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	endpoints := []struct {
+		name     string
+		endpoint Endpoint
+	}{
+		{Orchestrator, c.Orchestrator},
+		{Order, c.Order},
+		{Payment, c.Payment},
+		{Shipping, c.Shipping},
+	}
+	for _, e := range endpoints {
+		if e.endpoint.Addr == "" {
+			errs = append(errs, fmt.Errorf("%s: addr is required", e.name))
+		}
+		u, err := url.Parse(e.endpoint.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s: url %q must be an absolute http(s) URL", e.name, e.endpoint.URL))
+		}
+	}
+
+	switch c.Transport {
+	case TransportHTTP:
+	case TransportGRPC:
+		for _, e := range endpoints[1:] {
+			if e.endpoint.GRPCTarget == "" {
+				errs = append(errs, fmt.Errorf("%s: grpc_target is required with the grpc transport", e.name))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("transport %q must be %q or %q", c.Transport, TransportHTTP, TransportGRPC))
+	}
+
+	if c.HTTPTimeout <= 0 {
+		errs = append(errs, errors.New("http_timeout must be positive"))
+	}
+	if c.RetryAttempts < 1 {
+		errs = append(errs, errors.New("retry_attempts must be at least 1"))
+	}
+	if c.RetryBackoff < 0 {
+		errs = append(errs, errors.New("retry_backoff cannot be negative"))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("shutdown_timeout must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Duration is a time.Duration written as a string such as "10s" in config
+// files.
+type Duration time.Duration
+
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10s\": %w", err)
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) parse(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		args  []string
+		check func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "fraud blocklist from the environment",
+			env:  map[string]string{"SAGA_FRAUD_BLOCKLIST_FILE": "/etc/blocklist.json"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.FraudBlocklistFile != "/etc/blocklist.json" {
+					t.Errorf("FraudBlocklistFile = %q", cfg.FraudBlocklistFile)
+				}
+			},
+		},
+		{
+			name: "retry and shutdown flags",
+			args: []string{"-retry-attempts", "5", "-retry-backoff", "1s", "-shutdown-timeout", "30s"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.RetryAttempts != 5 || cfg.RetryBackoff.Std() != time.Second || cfg.ShutdownTimeout.Std() != 30*time.Second {
+					t.Errorf("retry_attempts %d, retry_backoff %s, shutdown_timeout %s", cfg.RetryAttempts, cfg.RetryBackoff.Std(), cfg.ShutdownTimeout.Std())
+				}
+			},
+		},
+		{
+			name: "flags override the environment",
+			env:  map[string]string{"SAGA_RETRY_ATTEMPTS": "2", "SAGA_RETRY_BACKOFF": "50ms"},
+			args: []string{"-retry-attempts", "4"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.RetryAttempts != 4 || cfg.RetryBackoff.Std() != 50*time.Millisecond {
+					t.Errorf("retry_attempts %d, retry_backoff %s", cfg.RetryAttempts, cfg.RetryBackoff.Std())
+				}
+			},
+		},
+		{
+			name: "saga store file flag",
+			args: []string{"-saga-store-file", "/var/lib/sagas.json"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.SagaStoreFile != "/var/lib/sagas.json" {
+					t.Errorf("SagaStoreFile = %q", cfg.SagaStoreFile)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			cfg, err := Load(Orchestrator, tt.args)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadRejectsInvalidFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"-retry-attempts", "0"},
+		{"-retry-backoff", "-1s"},
+		{"-shutdown-timeout", "0s"},
+	} {
+		if _, err := Load(Orchestrator, args); err == nil {
+			t.Errorf("Load(%v) succeeded", args)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package etag maps entity version numbers to HTTP entity tags so that
+// services can refuse updates made against a stale version. Every operation
+// that changes an order, payment or shipment checks its If-Match
+// precondition; returns, stock and wallets carry no version and take no
+// precondition.
+package etag
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+)
+
+func Format(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Set sets the response's ETag header to the entity version.
+func Set(c *gin.Context, version int) {
+	c.Header("ETag", Format(version))
+}
+
+// Precondition is the If-Match header of a request changing a versioned
+// entity. The empty Precondition, like "*", matches every version, which is
+// what callers that do not track versions, such as gRPC callers, pass.
+type Precondition string
+
+// IfMatch is the precondition of the request.
+func IfMatch(c *gin.Context) Precondition {
+	return Precondition(c.GetHeader("If-Match"))
+}
+
+// Matches reports whether the precondition names the entity version.
+func (p Precondition) Matches(version int) bool {
+	if p == "" || p == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(string(p), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == Format(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns a 412 Precondition Failed error, carrying the current
+// version, when the precondition does not match the entity version.
+func (p Precondition) Check(version int) error {
+	if p.Matches(version) {
+		return nil
+	}
+
+	mismatch := apierror.New(http.StatusPreconditionFailed, apierror.CodePreconditionFailed,
+		fmt.Sprintf("If-Match %s does not match current version %s", p, Format(version)))
+	return mismatch.WithDetail("version", version)
+}
+
+// Respond writes err as the response like apierror.Respond. A failed
+// precondition also sets the ETag header to the current version.
+func Respond(c *gin.Context, err error) {
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) && apiErr.Code == apierror.CodePreconditionFailed {
+		if version, ok := apiErr.Details["version"].(int); ok {
+			Set(c, version)
+		}
+	}
+	apierror.Respond(c, err)
+}
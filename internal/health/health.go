@@ -0,0 +1,111 @@
+// Package health serves the liveness and readiness endpoints shared by all
+// binaries.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check reports whether one dependency is usable.
+type Check func(ctx context.Context) error
+
+type checkResult struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// Register adds GET /healthz, which answers as long as the process serves
+// requests, and GET /readyz, which runs every check concurrently within
+// timeout and answers 503 with per-check details if any of them fails.
+func Register(router *gin.Engine, timeout time.Duration, checks map[string]Check) {
+	router.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	router.GET("/readyz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		results := make(map[string]checkResult, len(checks))
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		for name, check := range checks {
+			wg.Add(1)
+			go func(name string, check Check) {
+				defer wg.Done()
+
+				start := time.Now()
+				err := check(ctx)
+				result := checkResult{Status: "ok", LatencyMs: time.Since(start).Milliseconds()}
+				if err != nil {
+					result.Status = "error"
+					result.Error = err.Error()
+				}
+
+				mu.Lock()
+				results[name] = result
+				mu.Unlock()
+			}(name, check)
+		}
+		wg.Wait()
+
+		status, code := "ready", http.StatusOK
+		for _, result := range results {
+			if result.Status != "ok" {
+				status, code = "not_ready", http.StatusServiceUnavailable
+				break
+			}
+		}
+
+		c.JSON(code, gin.H{"status": status, "checks": results})
+	})
+}
+
+// HTTP checks that GET url answers 200 OK.
+func HTTP(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s returned status code %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// Lockable checks that mu can be read-locked, i.e. that the in-memory store
+// it guards is not held by a stuck writer.
+func Lockable(ctx context.Context, mu *sync.RWMutex) error {
+	locked := make(chan struct{})
+	go func() {
+		mu.RLock()
+		mu.RUnlock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		return errors.New("store is locked")
+	}
+}
@@ -0,0 +1,192 @@
+// Package logging writes structured JSON log lines. Its API follows
+// log/slog: messages carry alternating key/value arguments, and loggers made
+// with With add their attributes to every line.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+type attr struct {
+	key   string
+	value interface{}
+}
+
+// Logger writes one JSON object per line. It is safe for concurrent use,
+// and loggers derived with With share the writer.
+type Logger struct {
+	out   *output
+	attrs []attr
+}
+
+type output struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// New creates a logger writing to w whose lines name service.
+func New(w io.Writer, service string) *Logger {
+	return (&Logger{out: &output{w: w}}).With("service", service)
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger = &Logger{out: &output{w: os.Stderr}}
+)
+
+// Default is the logger used where no request context is at hand.
+func Default() *Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// SetDefault makes l the default logger and, like slog.SetDefault, sends
+// the standard log package's output through it.
+func SetDefault(l *Logger) {
+	defaultMu.Lock()
+	defaultLogger = l
+	defaultMu.Unlock()
+
+	log.SetFlags(0)
+	log.SetOutput(stdWriter{l})
+}
+
+type stdWriter struct {
+	l *Logger
+}
+
+func (w stdWriter) Write(p []byte) (int, error) {
+	w.l.Info(string(bytes.TrimRight(p, "\n")))
+	return len(p), nil
+}
+
+// With returns a logger adding args to every line. A key given again
+// replaces its earlier value.
+func (l *Logger) With(args ...interface{}) *Logger {
+	attrs := make([]attr, len(l.attrs), len(l.attrs)+len(args)/2)
+	copy(attrs, l.attrs)
+	return &Logger{out: l.out, attrs: appendAttrs(attrs, args)}
+}
+
+func (l *Logger) Debug(msg string, args ...interface{}) { l.Log(LevelDebug, msg, args...) }
+func (l *Logger) Info(msg string, args ...interface{})  { l.Log(LevelInfo, msg, args...) }
+func (l *Logger) Warn(msg string, args ...interface{})  { l.Log(LevelWarn, msg, args...) }
+func (l *Logger) Error(msg string, args ...interface{}) { l.Log(LevelError, msg, args...) }
+
+// Log writes msg at level with the logger's attributes followed by args.
+func (l *Logger) Log(level Level, msg string, args ...interface{}) {
+	attrs := make([]attr, 0, 3+len(l.attrs)+len(args)/2)
+	attrs = append(attrs,
+		attr{"time", time.Now().Format(time.RFC3339Nano)},
+		attr{"level", level.String()},
+		attr{"msg", msg},
+	)
+	attrs = append(attrs, l.attrs...)
+	attrs = appendAttrs(attrs, args)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, a := range attrs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(a.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(encodeValue(a.value))
+	}
+	buf.WriteString("}\n")
+
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	l.out.w.Write(buf.Bytes())
+}
+
+// appendAttrs pairs up args as keys and values. As in slog, a value without
+// a key is logged under !BADKEY.
+func appendAttrs(attrs []attr, args []interface{}) []attr {
+	for len(args) > 0 {
+		key, ok := args[0].(string)
+		if !ok || len(args) == 1 {
+			attrs = setAttr(attrs, attr{"!BADKEY", args[0]})
+			args = args[1:]
+			continue
+		}
+		attrs = setAttr(attrs, attr{key, args[1]})
+		args = args[2:]
+	}
+	return attrs
+}
+
+func setAttr(attrs []attr, a attr) []attr {
+	for i := range attrs {
+		if attrs[i].key == a.key {
+			attrs[i] = a
+			return attrs
+		}
+	}
+	return append(attrs, a)
+}
+
+// encodeValue writes errors and Stringers, durations included, as their
+// text and anything else as JSON.
+func encodeValue(v interface{}) []byte {
+	switch t := v.(type) {
+	case error:
+		v = t.Error()
+	case fmt.Stringer:
+		v = t.String()
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return data
+}
+
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext is the logger carried by ctx, or the default logger.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok {
+		return l
+	}
+	return Default()
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lines decodes the JSON lines written to buf.
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		out = append(out, fields)
+	}
+	return out
+}
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *Logger)
+		want map[string]interface{}
+	}{
+		{
+			name: "attributes and arguments",
+			log:  func(l *Logger) { l.Info("Order created", "order_id", "o1", "items", 2) },
+			want: map[string]interface{}{"level": "INFO", "msg": "Order created", "service": "test", "order_id": "o1", "items": 2.0},
+		},
+		{
+			name: "with replaces a repeated key",
+			log:  func(l *Logger) { l.With("saga_id", "s1").With("saga_id", "s2").Warn("Retrying") },
+			want: map[string]interface{}{"level": "WARN", "msg": "Retrying", "service": "test", "saga_id": "s2"},
+		},
+		{
+			name: "errors and durations as text",
+			log:  func(l *Logger) { l.Error("Failed", "error", errors.New("boom"), "backoff", 2*time.Second) },
+			want: map[string]interface{}{"level": "ERROR", "msg": "Failed", "service": "test", "error": "boom", "backoff": "2s"},
+		},
+		{
+			name: "value without a key",
+			log:  func(l *Logger) { l.Info("Odd", "key", "value", "dangling") },
+			want: map[string]interface{}{"level": "INFO", "msg": "Odd", "service": "test", "key": "value", "!BADKEY": "dangling"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(New(&buf, "test"))
+
+			got := lines(t, &buf)
+			if len(got) != 1 {
+				t.Fatalf("wrote %d lines, want 1", len(got))
+			}
+			if _, ok := got[0]["time"]; !ok {
+				t.Error("line has no time")
+			}
+			delete(got[0], "time")
+			if len(got[0]) != len(tt.want) {
+				t.Errorf("line %v, want %v", got[0], tt.want)
+			}
+			for key, value := range tt.want {
+				if got[0][key] != value {
+					t.Errorf("%s = %v, want %v", key, got[0][key], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"saga-order-system/internal/models"
+)
+
+// RequestIDHeader carries the request ID between services. The saga ID
+// travels in models.SagaIDHeader.
+const RequestIDHeader = "X-Request-ID"
+
+type idsKey struct{}
+
+type ids struct {
+	requestID string
+	sagaID    string
+}
+
+// RequestID is the ID of the request ctx belongs to.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(idsKey{}).(ids)
+	return id.requestID
+}
+
+// SagaID is the ID of the saga the request ctx belongs to was made for.
+func SagaID(ctx context.Context) string {
+	id, _ := ctx.Value(idsKey{}).(ids)
+	return id.sagaID
+}
+
+// ContextWithIDs returns a copy of ctx carrying the request and saga IDs,
+// for work done on behalf of a request outside its handler.
+func ContextWithIDs(ctx context.Context, requestID, sagaID string) context.Context {
+	return context.WithValue(ctx, idsKey{}, ids{requestID: requestID, sagaID: sagaID})
+}
+
+// Propagate sets the correlation headers of an outgoing request made while
+// handling ctx.
+func Propagate(ctx context.Context, header http.Header) {
+	id, _ := ctx.Value(idsKey{}).(ids)
+	if id.requestID != "" {
+		header.Set(RequestIDHeader, id.requestID)
+	}
+	if id.sagaID != "" {
+		header.Set(models.SagaIDHeader, id.sagaID)
+	}
+}
+
+// Middleware gives every request a logger carrying its request ID, taken
+// from the X-Request-ID header or generated, and the saga ID from the
+// X-Saga-ID header, then logs the request once it has been handled. It
+// replaces gin's own request logger.
+func Middleware(base *Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := ids{
+			requestID: c.GetHeader(RequestIDHeader),
+			sagaID:    c.GetHeader(models.SagaIDHeader),
+		}
+		if id.requestID == "" {
+			id.requestID = uuid.New().String()
+		}
+		c.Header(RequestIDHeader, id.requestID)
+
+		logger := base.With("request_id", id.requestID)
+		if id.sagaID != "" {
+			logger = logger.With("saga_id", id.sagaID)
+		}
+		ctx := ContextWithIDs(c.Request.Context(), id.requestID, id.sagaID)
+		c.Request = c.Request.WithContext(NewContext(ctx, logger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = LevelError
+		case status >= http.StatusBadRequest:
+			level = LevelWarn
+		}
+		args := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip", c.ClientIP(),
+		}
+		if len(c.Errors) > 0 {
+			args = append(args, "error", c.Errors.String())
+		}
+		logger.Log(level, "Request handled", args...)
+	}
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/models"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		sagaID    string
+		status    int
+		wantLevel string
+	}{
+		{
+			name:      "incoming IDs kept",
+			requestID: "r1",
+			sagaID:    "s1",
+			status:    http.StatusOK,
+			wantLevel: "INFO",
+		},
+		{
+			name:      "request ID generated",
+			status:    http.StatusNotFound,
+			wantLevel: "WARN",
+		},
+		{
+			name:      "server error",
+			requestID: "r1",
+			status:    http.StatusInternalServerError,
+			wantLevel: "ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(Middleware(New(&buf, "test")))
+
+			outgoing := http.Header{}
+			router.GET("/orders/:id", func(c *gin.Context) {
+				FromContext(c.Request.Context()).Info("Handling")
+				Propagate(c.Request.Context(), outgoing)
+				c.Status(tt.status)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/orders/o1", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			if tt.sagaID != "" {
+				req.Header.Set(models.SagaIDHeader, tt.sagaID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			if requestID == "" || tt.requestID != "" && requestID != tt.requestID {
+				t.Fatalf("response request ID %q, want %q", requestID, tt.requestID)
+			}
+			if got := outgoing.Get(RequestIDHeader); got != requestID {
+				t.Errorf("propagated request ID %q, want %q", got, requestID)
+			}
+			if got := outgoing.Get(models.SagaIDHeader); got != tt.sagaID {
+				t.Errorf("propagated saga ID %q, want %q", got, tt.sagaID)
+			}
+
+			// The handler's line and the request line both carry the IDs.
+			got := lines(t, &buf)
+			if len(got) != 2 {
+				t.Fatalf("wrote %d lines, want 2", len(got))
+			}
+			for _, line := range got {
+				if line["request_id"] != requestID {
+					t.Errorf("line %v has request_id %v, want %s", line["msg"], line["request_id"], requestID)
+				}
+				if tt.sagaID != "" && line["saga_id"] != tt.sagaID {
+					t.Errorf("line %v has saga_id %v, want %s", line["msg"], line["saga_id"], tt.sagaID)
+				}
+			}
+			if got[1]["level"] != tt.wantLevel || got[1]["status"] != float64(tt.status) {
+				t.Errorf("request line %v, want level %s", got[1], tt.wantLevel)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import "strings"
+
+// Address is a structured postal address. Country holds an ISO 3166-1
+// alpha-2 code once normalized.
+type Address struct {
+	Recipient  string   `json:"recipient" binding:"required"`
+	Lines      []string `json:"lines" binding:"required,min=1,max=3"`
+	City       string   `json:"city" binding:"required"`
+	Region     string   `json:"region"`
+	PostalCode string   `json:"postal_code"`
+	Country    string   `json:"country" binding:"required"`
+	Phone      string   `json:"phone"`
+}
+
+// String formats the address on a single line, e.g. for comparisons and
+// logging.
+func (a Address) String() string {
+	parts := append([]string{}, a.Lines...)
+	parts = append(parts, a.City)
+	if a.Region != "" {
+		parts = append(parts, a.Region)
+	}
+	if a.PostalCode != "" {
+		parts = append(parts, a.PostalCode)
+	}
+	parts = append(parts, a.Country)
+	return strings.Join(parts, ", ")
+}
+
+func (a Address) IsZero() bool {
+	return a.Recipient == "" && len(a.Lines) == 0 && a.City == "" && a.Country == ""
+}
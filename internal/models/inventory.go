@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type StockLevel struct {
+	ProductID string    `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type StockAdjustmentRequest struct {
+	Items []ShipmentItem `json:"items" binding:"required,min=1,dive"`
+	// Returned marks units coming back from a return. Those of products
+	// whose stock is not tracked are not put back, so that the products
+	// stay untracked.
+	Returned bool `json:"returned"`
+	// Reference, when set, names the adjustment so that one retried after
+	// a lost response is only applied once.
+	Reference string `json:"reference,omitempty"`
+}
+
+// InventoryReservation is the stock held for an order. Only products that
+// are stocked are held; Items lists what was actually taken off hand.
+type InventoryReservation struct {
+	OrderID   string         `json:"order_id"`
+	Items     []ShipmentItem `json:"items"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type ReserveStockRequest struct {
+	OrderID string         `json:"order_id" binding:"required"`
+	Items   []ShipmentItem `json:"items" binding:"required,min=1,dive"`
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPending            OrderStatus = "PENDING"
+	OrderStatusPartiallyShipped   OrderStatus = "PARTIALLY_SHIPPED"
+	OrderStatusShipped            OrderStatus = "SHIPPED"
+	OrderStatusPartiallyDelivered OrderStatus = "PARTIALLY_DELIVERED"
+	OrderStatusCompleted          OrderStatus = "COMPLETED"
+	OrderStatusCancelled          OrderStatus = "CANCELLED"
+)
+
+type Order struct {
+	ID              string           `json:"id"`
+	UserID          string           `json:"user_id"`
+	Items           []OrderItem      `json:"items"`
+	TotalPrice      float64          `json:"total_price"`
+	ShippingAddress Address          `json:"shipping_address"`
+	Shipments       []ShipmentUpdate `json:"shipments"`
+	Status          OrderStatus      `json:"status"`
+	Lock            *OrderLock       `json:"lock,omitempty"`
+	Version         int              `json:"version"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// Touch records a write to the order by bumping its version.
+func (o *Order) Touch() {
+	o.Version++
+	o.UpdatedAt = time.Now()
+}
+
+// SagaIDHeader identifies the saga on whose behalf a request is made. It
+// ties the request's logs to the saga, and lets requests from the saga
+// holding an order's lock get through.
+const SagaIDHeader = "X-Saga-ID"
+
+// OrderLock is a semantic lock held by the saga currently changing the
+// order. It lapses at ExpiresAt so that a saga which never finishes cannot
+// hold the order forever. SagaID is never shown to clients: whoever sends
+// it in X-Saga-ID gets through the lock.
+type OrderLock struct {
+	SagaID    string    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LockedAgainst reports whether another saga than sagaID holds a live lock
+// on the order.
+func (o Order) LockedAgainst(sagaID string) bool {
+	return o.Lock != nil && o.Lock.SagaID != sagaID && time.Now().Before(o.Lock.ExpiresAt)
+}
+
+type OrderItem struct {
+	ProductID string  `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
+
+type CreateOrderRequest struct {
+	UserID          string      `json:"user_id" binding:"required"`
+	Items           []OrderItem `json:"items" binding:"required,min=1"`
+	ShippingAddress Address     `json:"shipping_address" binding:"required"`
+}
+
+type OrderResponse struct {
+	ID              string           `json:"id"`
+	UserID          string           `json:"user_id"`
+	Items           []OrderItem      `json:"items"`
+	TotalPrice      float64          `json:"total_price"`
+	ShippingAddress Address          `json:"shipping_address"`
+	Shipments       []ShipmentUpdate `json:"shipments"`
+	Status          OrderStatus      `json:"status"`
+	Lock            *OrderLock       `json:"lock,omitempty"`
+	Version         int              `json:"version"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+type CancelOrderRequest struct {
+	OrderID string `json:"order_id" binding:"required"`
+}
+
+// LockOrderRequest takes or refreshes the lock on an order for a saga.
+// TTLSeconds defaults to the order service's lock TTL.
+type LockOrderRequest struct {
+	SagaID     string `json:"saga_id" binding:"required"`
+	TTLSeconds int    `json:"ttl_seconds" binding:"min=0"`
+}
+
+type UnlockOrderRequest struct {
+	SagaID string `json:"saga_id" binding:"required"`
+}
+
+// ModifyOrderRequest replaces the items and/or the shipping address of an
+// order that has not been dispatched yet.
+type ModifyOrderRequest struct {
+	Items           []OrderItem `json:"items" binding:"omitempty,min=1"`
+	ShippingAddress *Address    `json:"shipping_address"`
+}
+
+func TotalPrice(items []OrderItem) float64 {
+	var totalPrice float64
+	for _, item := range items {
+		totalPrice += item.Price * float64(item.Quantity)
+	}
+	return totalPrice
+}
+
+func NewOrder(req CreateOrderRequest) Order {
+	totalPrice := TotalPrice(req.Items)
+
+	now := time.Now()
+	return Order{
+		ID:              uuid.New().String(),
+		UserID:          req.UserID,
+		Items:           req.Items,
+		TotalPrice:      totalPrice,
+		ShippingAddress: req.ShippingAddress,
+		Shipments:       []ShipmentUpdate{},
+		Status:          OrderStatusPending,
+		Version:         1,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "PENDING"
+	PaymentStatusRequiresAction    PaymentStatus = "REQUIRES_ACTION"
+	PaymentStatusPendingReview     PaymentStatus = "PENDING_REVIEW"
+	PaymentStatusRejected          PaymentStatus = "REJECTED"
+	PaymentStatusSuccess           PaymentStatus = "SUCCESS"
+	PaymentStatusFailed            PaymentStatus = "FAILED"
+	PaymentStatusRefunded          PaymentStatus = "REFUNDED"
+	PaymentStatusPartiallyRefunded PaymentStatus = "PARTIALLY_REFUNDED"
+	PaymentStatusVoided            PaymentStatus = "VOIDED"
+)
+
+type PaymentMethodType string
+
+const (
+	PaymentMethodCard         PaymentMethodType = "CARD"
+	PaymentMethodWallet       PaymentMethodType = "WALLET"
+	PaymentMethodBankTransfer PaymentMethodType = "BANK_TRANSFER"
+)
+
+type PaymentMethod struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Type      PaymentMethodType `json:"type"`
+	CardToken string            `json:"card_token,omitempty"`
+	CardLast4 string            `json:"card_last4,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type CreatePaymentMethodRequest struct {
+	UserID     string            `json:"user_id" binding:"required"`
+	Type       PaymentMethodType `json:"type" binding:"required,oneof=CARD WALLET BANK_TRANSFER"`
+	CardNumber string            `json:"card_number" binding:"required_if=Type CARD"`
+}
+
+type Payment struct {
+	ID              string            `json:"id"`
+	OrderID         string            `json:"order_id"`
+	UserID          string            `json:"user_id,omitempty"`
+	Amount          float64           `json:"amount"`
+	RefundedAmount  float64           `json:"refunded_amount"`
+	Method          PaymentMethodType `json:"method"`
+	PaymentMethodID string            `json:"payment_method_id,omitempty"`
+	Status          PaymentStatus     `json:"status"`
+	GatewayRef      string            `json:"gateway_ref,omitempty"`
+	FailureReason   string            `json:"failure_reason,omitempty"`
+	FraudScore      int               `json:"fraud_score"`
+	FraudReasons    []string          `json:"fraud_reasons,omitempty"`
+	CallbackURL     string            `json:"-"`
+	Version         int               `json:"version"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// Touch records a write to the payment by bumping its version.
+func (p *Payment) Touch() {
+	p.Version++
+	p.UpdatedAt = time.Now()
+}
+
+// ProcessPaymentRequest pays with the saved method PaymentMethodID when set,
+// otherwise with the one-off CardNumber. CallbackURL is notified when a
+// payment held for fraud review is decided.
+type ProcessPaymentRequest struct {
+	OrderID         string   `json:"order_id" binding:"required"`
+	UserID          string   `json:"user_id"`
+	Amount          float64  `json:"amount" binding:"required"`
+	PaymentMethodID string   `json:"payment_method_id"`
+	CardNumber      string   `json:"card_number"`
+	BillingAddress  *Address `json:"billing_address"`
+	ShippingAddress *Address `json:"shipping_address"`
+	CallbackURL     string   `json:"callback_url"`
+}
+
+// RefundPaymentRequest refunds Amount of an order's payments, or all of it
+// when Amount is zero.
+type RefundPaymentRequest struct {
+	OrderID string  `json:"order_id" binding:"required"`
+	Amount  float64 `json:"amount" binding:"gte=0"`
+	// Reference, when set, names the refund so that one retried after a
+	// lost response is only made once.
+	Reference string `json:"reference,omitempty"`
+}
+
+// VoidPaymentRequest releases the order's payments still held for fraud
+// review.
+type VoidPaymentRequest struct {
+	OrderID string `json:"order_id" binding:"required"`
+}
+
+type ReviewPaymentRequest struct {
+	Approved bool   `json:"approved"`
+	Note     string `json:"note"`
+}
+
+type PaymentReviewNotification struct {
+	PaymentID string        `json:"payment_id"`
+	OrderID   string        `json:"order_id"`
+	Approved  bool          `json:"approved"`
+	Status    PaymentStatus `json:"status"`
+	Note      string        `json:"note,omitempty"`
+}
+
+type PaymentResponse struct {
+	ID             string            `json:"id"`
+	OrderID        string            `json:"order_id"`
+	Amount         float64           `json:"amount"`
+	RefundedAmount float64           `json:"refunded_amount"`
+	Method         PaymentMethodType `json:"method"`
+	Status         PaymentStatus     `json:"status"`
+	FailureReason  string            `json:"failure_reason,omitempty"`
+	FraudScore     int               `json:"fraud_score"`
+	Version        int               `json:"version"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+func NewPayment(req ProcessPaymentRequest) Payment {
+	now := time.Now()
+	return Payment{
+		ID:          uuid.New().String(),
+		OrderID:     req.OrderID,
+		UserID:      req.UserID,
+		Amount:      req.Amount,
+		Method:      PaymentMethodCard,
+		Status:      PaymentStatusPending,
+		CallbackURL: req.CallbackURL,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func NewPaymentMethod(req CreatePaymentMethodRequest) PaymentMethod {
+	return PaymentMethod{
+		ID:        uuid.New().String(),
+		UserID:    req.UserID,
+		Type:      req.Type,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReturnStatus string
+
+const (
+	ReturnStatusOpen      ReturnStatus = "OPEN"
+	ReturnStatusReceived  ReturnStatus = "RECEIVED"
+	ReturnStatusCompleted ReturnStatus = "COMPLETED"
+	ReturnStatusCancelled ReturnStatus = "CANCELLED"
+)
+
+// Return is a return merchandise authorization for part of an order.
+type Return struct {
+	ID            string         `json:"id"`
+	OrderID       string         `json:"order_id"`
+	Items         []ShipmentItem `json:"items"`
+	Reason        string         `json:"reason"`
+	RefundAmount  float64        `json:"refund_amount"`
+	PickupAddress Address        `json:"pickup_address"`
+	Status        ReturnStatus   `json:"status"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+type CreateReturnRequest struct {
+	OrderID string         `json:"order_id" binding:"required"`
+	Items   []ShipmentItem `json:"items" binding:"required,min=1,dive"`
+	Reason  string         `json:"reason"`
+}
+
+func NewReturn(req CreateReturnRequest, order Order) Return {
+	prices := make(map[string]float64)
+	for _, item := range order.Items {
+		prices[item.ProductID] = item.Price
+	}
+
+	var refund float64
+	for _, item := range req.Items {
+		refund += prices[item.ProductID] * float64(item.Quantity)
+	}
+
+	now := time.Now()
+	return Return{
+		ID:            uuid.New().String(),
+		OrderID:       req.OrderID,
+		Items:         req.Items,
+		Reason:        req.Reason,
+		RefundAmount:  refund,
+		PickupAddress: order.ShippingAddress,
+		Status:        ReturnStatusOpen,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
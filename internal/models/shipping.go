@@ -0,0 +1,200 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ShippingStatus string
+
+const (
+	ShippingStatusPending        ShippingStatus = "PENDING"
+	ShippingStatusLabelCreated   ShippingStatus = "LABEL_CREATED"
+	ShippingStatusPickedUp       ShippingStatus = "PICKED_UP"
+	ShippingStatusInTransit      ShippingStatus = "IN_TRANSIT"
+	ShippingStatusOutForDelivery ShippingStatus = "OUT_FOR_DELIVERY"
+	ShippingStatusDelivered      ShippingStatus = "DELIVERED"
+	ShippingStatusReturned       ShippingStatus = "RETURNED"
+	ShippingStatusCancelled      ShippingStatus = "CANCELLED"
+)
+
+// shippingTransitions lists the statuses a shipment may move to from each
+// status. IN_TRANSIT may repeat for intermediate scans, and a failed
+// delivery attempt sends the shipment back to IN_TRANSIT.
+var shippingTransitions = map[ShippingStatus][]ShippingStatus{
+	ShippingStatusPending:        {ShippingStatusLabelCreated, ShippingStatusCancelled},
+	ShippingStatusLabelCreated:   {ShippingStatusPickedUp, ShippingStatusCancelled},
+	ShippingStatusPickedUp:       {ShippingStatusInTransit, ShippingStatusReturned},
+	ShippingStatusInTransit:      {ShippingStatusInTransit, ShippingStatusOutForDelivery, ShippingStatusReturned},
+	ShippingStatusOutForDelivery: {ShippingStatusDelivered, ShippingStatusInTransit, ShippingStatusReturned},
+}
+
+func (s ShippingStatus) CanTransitionTo(next ShippingStatus) bool {
+	for _, allowed := range shippingTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type ShipmentKind string
+
+const (
+	ShipmentKindOutbound ShipmentKind = "OUTBOUND"
+	// ShipmentKindReturn shipments are picked up at Address and delivered
+	// to the warehouse.
+	ShipmentKindReturn ShipmentKind = "RETURN"
+)
+
+type CarrierSelection string
+
+const (
+	CarrierSelectionCheapest CarrierSelection = "CHEAPEST"
+	CarrierSelectionFastest  CarrierSelection = "FASTEST"
+)
+
+type ShippingRate struct {
+	Carrier       string  `json:"carrier"`
+	Service       string  `json:"service"`
+	Price         float64 `json:"price"`
+	EstimatedDays int     `json:"estimated_days"`
+}
+
+type ShippingQuoteRequest struct {
+	Address  Address `json:"address" binding:"required"`
+	WeightKg float64 `json:"weight_kg" binding:"gte=0"`
+	Carrier  string  `json:"carrier"`
+}
+
+type TrackingEvent struct {
+	Status      ShippingStatus `json:"status"`
+	Location    string         `json:"location,omitempty"`
+	Description string         `json:"description,omitempty"`
+	OccurredAt  time.Time      `json:"occurred_at"`
+}
+
+// IsShipped reports whether the parcel has left the warehouse.
+func (s ShippingStatus) IsShipped() bool {
+	switch s {
+	case ShippingStatusPickedUp, ShippingStatusInTransit, ShippingStatusOutForDelivery, ShippingStatusDelivered:
+		return true
+	}
+	return false
+}
+
+// ShipmentItem is the part of an order line packed into one shipment.
+type ShipmentItem struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,gt=0"`
+}
+
+// ShipmentUpdate is sent to the order service whenever a shipment changes
+// status, so the order can derive its fulfillment status.
+type ShipmentUpdate struct {
+	ShippingID string         `json:"shipping_id" binding:"required"`
+	Status     ShippingStatus `json:"status" binding:"required"`
+	Items      []ShipmentItem `json:"items"`
+}
+
+// Shipping is one package of an order. An empty Items list means the
+// package holds the whole order.
+type Shipping struct {
+	ID             string          `json:"id"`
+	OrderID        string          `json:"order_id"`
+	Kind           ShipmentKind    `json:"kind"`
+	Items          []ShipmentItem  `json:"items,omitempty"`
+	Address        Address         `json:"address"`
+	WeightKg       float64         `json:"weight_kg"`
+	Carrier        string          `json:"carrier"`
+	Service        string          `json:"service"`
+	TrackingNumber string          `json:"tracking_number"`
+	Cost           float64         `json:"cost"`
+	Status         ShippingStatus  `json:"status"`
+	Events         []TrackingEvent `json:"events"`
+	CallbackURL    string          `json:"-"`
+	Version        int             `json:"version"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// StartShippingRequest ships with the explicit Carrier (and optionally
+// Service) when given, otherwise with the rate picked by Selection, which
+// defaults to the cheapest. CallbackURL is notified when a return shipment
+// reaches the warehouse.
+type StartShippingRequest struct {
+	OrderID     string           `json:"order_id" binding:"required"`
+	Kind        ShipmentKind     `json:"kind" binding:"omitempty,oneof=OUTBOUND RETURN"`
+	CallbackURL string           `json:"callback_url"`
+	Items       []ShipmentItem   `json:"items" binding:"omitempty,dive"`
+	Address     Address          `json:"address" binding:"required"`
+	WeightKg    float64          `json:"weight_kg" binding:"gte=0"`
+	Carrier     string           `json:"carrier"`
+	Service     string           `json:"service"`
+	Selection   CarrierSelection `json:"selection" binding:"omitempty,oneof=CHEAPEST FASTEST"`
+}
+
+// CancelShippingRequest cancels the order's shipment ShippingID, or all its
+// outbound shipments when ShippingID is empty.
+type CancelShippingRequest struct {
+	OrderID    string `json:"order_id" binding:"required"`
+	ShippingID string `json:"shipping_id"`
+}
+
+type TrackingEventRequest struct {
+	Status      ShippingStatus `json:"status" binding:"required"`
+	Location    string         `json:"location"`
+	Description string         `json:"description"`
+	OccurredAt  time.Time      `json:"occurred_at"`
+}
+
+type ShippingResponse struct {
+	ID             string          `json:"id"`
+	OrderID        string          `json:"order_id"`
+	Kind           ShipmentKind    `json:"kind"`
+	Items          []ShipmentItem  `json:"items,omitempty"`
+	Address        Address         `json:"address"`
+	WeightKg       float64         `json:"weight_kg"`
+	Carrier        string          `json:"carrier"`
+	Service        string          `json:"service"`
+	TrackingNumber string          `json:"tracking_number"`
+	Cost           float64         `json:"cost"`
+	Status         ShippingStatus  `json:"status"`
+	Events         []TrackingEvent `json:"events"`
+	Version        int             `json:"version"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func NewShipping(req StartShippingRequest) Shipping {
+	kind := req.Kind
+	if kind == "" {
+		kind = ShipmentKindOutbound
+	}
+
+	now := time.Now()
+	return Shipping{
+		ID:          uuid.New().String(),
+		OrderID:     req.OrderID,
+		Kind:        kind,
+		CallbackURL: req.CallbackURL,
+		Items:       req.Items,
+		Address:     req.Address,
+		WeightKg:    req.WeightKg,
+		Status:      ShippingStatusPending,
+		Events:      []TrackingEvent{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Track records a tracking event and moves the shipment to its status.
+func (s *Shipping) Track(event TrackingEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	s.Events = append(s.Events, event)
+	s.Status = event.Status
+	s.Version++
+	s.UpdatedAt = time.Now()
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type Wallet struct {
+	UserID    string    `json:"user_id"`
+	Balance   float64   `json:"balance"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TopUpWalletRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
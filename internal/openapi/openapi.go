@@ -0,0 +1,192 @@
+// Package openapi builds the OpenAPI 3 document of a service from the Go
+// types its handlers bind and respond with, serves it, and validates
+// request bodies against it before they reach the handlers.
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+)
+
+const jsonContent = "application/json"
+
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+
+	// operations is keyed by method and gin route, e.g. "GET /orders/:id".
+	operations map[string]*Operation
+	types      map[reflect.Type]string
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Operation struct {
+	Summary     string              `json:"summary,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Message is the body of responses that only confirm what was done.
+type Message struct {
+	Message string `json:"message"`
+}
+
+// Op describes one route. Request is a value of the type the handler binds
+// the JSON body to, or nil when it takes no body. Responses maps each
+// success status to a value of the type responded with, or to nil when the
+// response has no body; error responses are documented for every route.
+type Op struct {
+	Summary   string
+	Request   interface{}
+	Responses map[int]interface{}
+}
+
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI:    "3.0.3",
+		Info:       Info{Title: title, Version: version},
+		Paths:      make(map[string]map[string]*Operation),
+		Components: Components{Schemas: make(map[string]*Schema)},
+		operations: make(map[string]*Operation),
+		types:      make(map[reflect.Type]string),
+	}
+}
+
+// Add documents the route registered on gin as method and path.
+func (d *Document) Add(method, path string, op Op) {
+	operation := &Operation{
+		Summary:   op.Summary,
+		Responses: make(map[string]Response),
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimPrefix(segment, ":")
+		segments[i] = "{" + name + "}"
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	if op.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  d.content(op.Request),
+		}
+	}
+
+	for status, body := range op.Responses {
+		response := Response{Description: http.StatusText(status)}
+		if body != nil {
+			response.Content = d.content(body)
+		}
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+	operation.Responses["default"] = Response{
+		Description: "Error",
+		Content:     d.content(apierror.Response{}),
+	}
+
+	openAPIPath := strings.Join(segments, "/")
+	if d.Paths[openAPIPath] == nil {
+		d.Paths[openAPIPath] = make(map[string]*Operation)
+	}
+	d.Paths[openAPIPath][strings.ToLower(method)] = operation
+	d.operations[method+" "+path] = operation
+}
+
+func (d *Document) content(v interface{}) map[string]MediaType {
+	return map[string]MediaType{
+		jsonContent: {Schema: d.schemaFor(reflect.TypeOf(v))},
+	}
+}
+
+// Handler serves the document as JSON.
+func (d *Document) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, d)
+	}
+}
+
+// operationalPaths are served by every binary next to its API and are left
+// out of its document.
+var operationalPaths = map[string]bool{
+	"/healthz":      true,
+	"/readyz":       true,
+	"/metrics":      true,
+	"/openapi.json": true,
+}
+
+// Verify checks that the document describes exactly the routes registered
+// on gin, so that a handler added or removed without updating the document
+// is caught at startup.
+func (d *Document) Verify(routes gin.RoutesInfo) error {
+	served := make(map[string]bool)
+	var problems []string
+	for _, route := range routes {
+		if operationalPaths[route.Path] {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		served[key] = true
+		if d.operations[key] == nil {
+			problems = append(problems, key+" is not documented")
+		}
+	}
+	for key := range d.operations {
+		if !served[key] {
+			problems = append(problems, key+" is documented but not served")
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
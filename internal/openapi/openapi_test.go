@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVerify(t *testing.T) {
+	handler := func(c *gin.Context) {}
+
+	tests := []struct {
+		name    string
+		routes  func(r *gin.Engine)
+		wantErr string
+	}{
+		{
+			name: "documented routes served",
+			routes: func(r *gin.Engine) {
+				r.GET("/orders/:id", handler)
+				r.POST("/orders", handler)
+				r.GET("/healthz", handler)
+			},
+		},
+		{
+			name: "route missing from the document",
+			routes: func(r *gin.Engine) {
+				r.GET("/orders/:id", handler)
+				r.POST("/orders", handler)
+				r.DELETE("/orders/:id", handler)
+			},
+			wantErr: "DELETE /orders/:id is not documented",
+		},
+		{
+			name: "documented route not served",
+			routes: func(r *gin.Engine) {
+				r.GET("/orders/:id", handler)
+			},
+			wantErr: "POST /orders is documented but not served",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New("Test", "1.0.0")
+			doc.Add(http.MethodGet, "/orders/:id", Op{})
+			doc.Add(http.MethodPost, "/orders", Op{Request: testRequest{}})
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			tt.routes(r)
+
+			err := doc.Verify(r.Routes())
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Verify() error = %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Fatalf("Verify() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
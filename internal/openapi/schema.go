@@ -0,0 +1,235 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// Schema is the subset of the OpenAPI schema object the generated
+// documents use.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor describes how t is encoded by encoding/json. Named structs are
+// added to the components and referenced.
+func (d *Document) schemaFor(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(d.schemaFor(t.Elem()))
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: d.schemaFor(t.Elem()), Nullable: true}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaFor(t.Elem()), Nullable: true}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		return d.ref(t)
+	}
+	// Interfaces hold any value.
+	return &Schema{}
+}
+
+func (d *Document) ref(t reflect.Type) *Schema {
+	if name, ok := d.types[t]; ok {
+		return &Schema{Ref: schemaRefPrefix + name}
+	}
+
+	// Two packages may use the same type name, e.g. for the request of
+	// their own create-order endpoint.
+	name := t.Name()
+	if _, taken := d.Components.Schemas[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = upperFirst(pkg) + name
+	}
+
+	// Register the name first so that recursive types refer to themselves.
+	d.types[t] = name
+	d.Components.Schemas[name] = &Schema{}
+	*d.Components.Schemas[name] = *d.structSchema(t)
+	return &Schema{Ref: schemaRefPrefix + name}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	d.addFields(s, t)
+	return s
+}
+
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitted := jsonName(field)
+		if omitted {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			d.addFields(s, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := d.schemaFor(field.Type)
+		if applyBinding(fieldSchema, field.Tag.Get("binding")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fieldSchema
+	}
+}
+
+// jsonName returns the name set by the field's json tag, if any, and
+// whether the tag leaves the field out.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, false
+}
+
+// applyBinding adds the constraints of a gin binding tag to s and reports
+// whether the tag makes the field required. Constraints after "dive" apply
+// to the elements.
+func applyBinding(s *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	required, omitempty := false, false
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "omitempty":
+			omitempty = true
+		case "dive":
+			if s.Items != nil {
+				applyBinding(s.Items, strings.Join(rules[i+1:], ","))
+			}
+			return required
+		case "oneof":
+			if s.Type != "string" {
+				break
+			}
+			s.Enum = strings.Fields(value)
+			// The empty value skips validation.
+			if omitempty {
+				s.Enum = append(s.Enum, "")
+			}
+		case "min", "max", "gt", "gte":
+			applyBound(s, key, value)
+		}
+	}
+
+	if required {
+		// gin refuses zero values of required fields, which for strings
+		// and slices can be told apart in the document.
+		s.Nullable = false
+		if s.Type == "string" && s.MinLength == nil && len(s.Enum) == 0 {
+			s.MinLength = intPtr(1)
+		}
+	}
+	return required
+}
+
+func applyBound(s *Schema, key, value string) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+
+	switch s.Type {
+	case "string":
+		switch key {
+		case "min":
+			s.MinLength = intPtr(int(n))
+		case "max":
+			s.MaxLength = intPtr(int(n))
+		}
+	case "array":
+		switch key {
+		case "min":
+			s.MinItems = intPtr(int(n))
+		case "max":
+			s.MaxItems = intPtr(int(n))
+		}
+	case "integer", "number":
+		switch key {
+		case "min", "gte":
+			s.Minimum = &n
+		case "gt":
+			s.Minimum = &n
+			s.ExclusiveMinimum = true
+		case "max":
+			s.Maximum = &n
+		}
+	}
+}
+
+// nullable allows null in place of a value of s. A reference cannot carry
+// other keywords, so it is wrapped.
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	s.Nullable = true
+	return s
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func upperFirst(s string) string {
+	for i, r := range s {
+		return string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+)
+
+// Validate refuses request bodies that do not match the document with 422
+// and the offending fields, before they reach the handler. Routes without a
+// documented body are passed through.
+func Validate(d *Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		operation := d.operations[c.Request.Method+" "+c.FullPath()]
+		if operation == nil || operation.RequestBody == nil {
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			apierror.Respond(c, apierror.InvalidRequest("Request body is not valid JSON: "+err.Error()))
+			c.Abort()
+			return
+		}
+
+		fields := make(map[string]string)
+		d.validate(operation.RequestBody.Content[jsonContent].Schema, value, "", fields)
+		if len(fields) > 0 {
+			apierror.Respond(c, apierror.ValidationFailed("Request body does not match the API specification").
+				WithDetail("fields", fields))
+			c.Abort()
+		}
+	}
+}
+
+// validate records in fields, keyed by path, why value does not match s.
+func (d *Document) validate(s *Schema, value interface{}, path string, fields map[string]string) {
+	if s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, schemaRefPrefix)]
+	}
+
+	if value == nil {
+		if !s.Nullable && (s.Type != "" || len(s.AllOf) > 0) {
+			fields[fieldName(path)] = "must not be null"
+		}
+		return
+	}
+	for _, sub := range s.AllOf {
+		d.validate(sub, value, path, fields)
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fields[fieldName(path)] = "must be an object"
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				fields[join(path, name)] = "is required"
+			}
+		}
+		for name, v := range object {
+			if property, ok := s.Properties[name]; ok {
+				d.validate(property, v, join(path, name), fields)
+			} else if s.AdditionalProperties != nil {
+				d.validate(s.AdditionalProperties, v, join(path, name), fields)
+			}
+		}
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fields[fieldName(path)] = "must be an array"
+			return
+		}
+		switch {
+		case s.MinItems != nil && len(items) < *s.MinItems && *s.MinItems == 1:
+			fields[fieldName(path)] = "must not be empty"
+		case s.MinItems != nil && len(items) < *s.MinItems:
+			fields[fieldName(path)] = fmt.Sprintf("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			fields[fieldName(path)] = fmt.Sprintf("must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range items {
+			d.validate(s.Items, item, path+"["+strconv.Itoa(i)+"]", fields)
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fields[fieldName(path)] = "must be a string"
+			return
+		}
+		length := utf8.RuneCountInString(str)
+		switch {
+		case len(s.Enum) > 0 && !contains(s.Enum, str):
+			fields[fieldName(path)] = "must be one of " + strings.Join(nonEmpty(s.Enum), ", ")
+		case s.MinLength != nil && length < *s.MinLength:
+			if *s.MinLength == 1 {
+				fields[fieldName(path)] = "must not be empty"
+			} else {
+				fields[fieldName(path)] = fmt.Sprintf("must be at least %d characters", *s.MinLength)
+			}
+		case s.MaxLength != nil && length > *s.MaxLength:
+			fields[fieldName(path)] = fmt.Sprintf("must be at most %d characters", *s.MaxLength)
+		case s.Format == "date-time":
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				fields[fieldName(path)] = "must be an RFC 3339 date-time"
+			}
+		}
+
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			fields[fieldName(path)] = "must be a number"
+			return
+		}
+		if s.Type == "integer" {
+			if _, err := number.Int64(); err != nil {
+				fields[fieldName(path)] = "must be an integer"
+				return
+			}
+		}
+		n, _ := number.Float64()
+		switch {
+		case s.Minimum != nil && s.ExclusiveMinimum && n <= *s.Minimum:
+			fields[fieldName(path)] = "must be greater than " + formatNumber(*s.Minimum)
+		case s.Minimum != nil && n < *s.Minimum:
+			fields[fieldName(path)] = "must be at least " + formatNumber(*s.Minimum)
+		case s.Maximum != nil && n > *s.Maximum:
+			fields[fieldName(path)] = "must be at most " + formatNumber(*s.Maximum)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fields[fieldName(path)] = "must be a boolean"
+		}
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// fieldName names the body itself when path is empty.
+func fieldName(path string) string {
+	if path == "" {
+		return "body"
+	}
+	return path
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
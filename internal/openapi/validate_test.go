@@ -0,0 +1,189 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testItem struct {
+	ProductID string  `json:"product_id" binding:"required"`
+	Quantity  int     `json:"quantity" binding:"required,gt=0"`
+	Price     float64 `json:"price" binding:"gte=0"`
+}
+
+type testAddress struct {
+	Lines []string `json:"lines" binding:"required,min=1,max=2"`
+}
+
+type testRequest struct {
+	UserID   string       `json:"user_id" binding:"required"`
+	Items    []testItem   `json:"items" binding:"required,min=1,dive"`
+	Address  *testAddress `json:"address"`
+	Kind     string       `json:"kind" binding:"omitempty,oneof=OUTBOUND RETURN"`
+	Gift     bool         `json:"gift"`
+	Deadline time.Time    `json:"deadline"`
+}
+
+func newTestRouter(method, path string, op Op) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	doc := New("Test", "1.0.0")
+	doc.Add(method, path, op)
+
+	router := gin.New()
+	router.Use(Validate(doc))
+	router.Handle(method, path, func(c *gin.Context) {
+		c.JSON(http.StatusOK, Message{Message: "ok"})
+	})
+	return router
+}
+
+func TestValidate(t *testing.T) {
+	router := newTestRouter(http.MethodPost, "/orders/:id", Op{Request: testRequest{}})
+	valid := `"user_id": "u1", "items": [{"product_id": "p1", "quantity": 1, "price": 10}]`
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantFields map[string]string
+	}{
+		{
+			name:       "valid",
+			body:       `{` + valid + `, "kind": "RETURN", "gift": true, "deadline": "2026-01-02T15:04:05Z"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "empty optional enum",
+			body:       `{` + valid + `, "kind": ""}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "not JSON",
+			body:       `{"user_id":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "body not an object",
+			body:       `[]`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: map[string]string{"body": "must be an object"},
+		},
+		{
+			name:       "required fields missing",
+			body:       `{}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: map[string]string{"user_id": "is required", "items": "is required"},
+		},
+		{
+			name:       "empty values",
+			body:       `{"user_id": "", "items": []}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: map[string]string{"user_id": "must not be empty", "items": "must not be empty"},
+		},
+		{
+			name:       "nested item fields",
+			body:       `{"user_id": "u1", "items": [{"product_id": "p1", "quantity": 0, "price": -1}, {"quantity": 1.5}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: map[string]string{
+				"items[0].quantity":   "must be greater than 0",
+				"items[0].price":      "must be at least 0",
+				"items[1].product_id": "is required",
+				"items[1].quantity":   "must be an integer",
+			},
+		},
+		{
+			name:       "wrong types",
+			body:       `{` + valid + `, "gift": "yes", "kind": "SIDEWAYS", "deadline": "tomorrow"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: map[string]string{
+				"gift":     "must be a boolean",
+				"kind":     "must be one of OUTBOUND, RETURN",
+				"deadline": "must be an RFC 3339 date-time",
+			},
+		},
+		{
+			name:       "nullable reference",
+			body:       `{` + valid + `, "address": null}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "referenced object checked",
+			body:       `{` + valid + `, "address": {"lines": ["a", "b", "c"]}}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: map[string]string{"address.lines": "must have at most 2 items"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/orders/o1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantFields == nil {
+				return
+			}
+
+			var resp struct {
+				Error struct {
+					Details struct {
+						Fields map[string]string `json:"fields"`
+					} `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if got := resp.Error.Details.Fields; !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidateKeepsBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	doc := New("Test", "1.0.0")
+	doc.Add(http.MethodPost, "/orders", Op{Request: testRequest{}})
+
+	router := gin.New()
+	router.Use(Validate(doc))
+	router.POST("/orders", func(c *gin.Context) {
+		var req testRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, req.UserID)
+	})
+	router.GET("/orders", func(c *gin.Context) {
+		c.String(http.StatusOK, "undocumented")
+	})
+
+	body := `{"user_id": "u1", "items": [{"product_id": "p1", "quantity": 1}]}`
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "u1" {
+		t.Fatalf("handler got %d %q, want the validated body", w.Code, w.Body)
+	}
+
+	// Routes without a documented body are passed through.
+	req = httptest.NewRequest(http.MethodGet, "/orders", strings.NewReader("not JSON"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("undocumented route status = %d", w.Code)
+	}
+}
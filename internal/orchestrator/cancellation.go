@@ -0,0 +1,169 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"saga-order-system/internal/models"
+)
+
+var (
+	ErrOrderNotCancellable = errors.New("order cannot be cancelled")
+	ErrOrderAlreadyShipped = errors.New("shipment has already been picked up")
+)
+
+// shippedStatuses are the shipment statuses after pickup, from which a
+// shipment can no longer be cancelled.
+var shippedStatuses = map[models.ShippingStatus]bool{
+	models.ShippingStatusPickedUp:       true,
+	models.ShippingStatusInTransit:      true,
+	models.ShippingStatusOutForDelivery: true,
+	models.ShippingStatusDelivered:      true,
+	models.ShippingStatusReturned:       true,
+}
+
+// CancelOrderSaga cancels an order on the customer's behalf: it stops the
+// shipment, refunds the payment and cancels the order. Orders that cannot
+// be cancelled, including those whose shipment has been picked up, are
+// refused with ErrOrderNotCancellable.
+func (o *Orchestrator) CancelOrderSaga(ctx context.Context, orderID string) (*Saga, error) {
+	saga := o.startSaga(SagaTypeCancelOrder)
+	saga.OrderID = orderID
+	o.sagas.save(saga)
+
+	return o.start(ctx, saga)
+}
+
+// cancelOrderDefinition is version 2 of the cancel saga, in which the
+// refund is the pivot and cancelling the order is retried rather than
+// undone.
+func (o *Orchestrator) cancelOrderDefinition() sagaDefinition {
+	return sagaDefinition{
+		sagaType:   SagaTypeCancelOrder,
+		version:    2,
+		locksOrder: true,
+		steps: []sagaStep{
+			{
+				name:   "check-order",
+				action: o.checkCancellable,
+			},
+			{
+				name:       "cancel-shipping",
+				action:     o.cancelShipping,
+				compensate: o.rebookShipments,
+			},
+			// Once the money is back the cancellation can only go forward.
+			{
+				name:   "refund-payment",
+				kind:   pivot,
+				action: o.refundOrder,
+			},
+			{
+				name:   "cancel-order",
+				kind:   retriable,
+				action: o.cancelOrder,
+			},
+		},
+	}
+}
+
+// cancelOrderDefinitionV1 is the cancel saga in which every step could be
+// compensated, so a failure to cancel the order rebooked its shipments.
+// Sagas started on it still finish on it.
+func (o *Orchestrator) cancelOrderDefinitionV1() sagaDefinition {
+	return sagaDefinition{
+		sagaType:   SagaTypeCancelOrder,
+		version:    1,
+		locksOrder: true,
+		steps: []sagaStep{
+			{
+				name:   "check-order",
+				action: o.checkCancellable,
+			},
+			{
+				name:       "cancel-shipping",
+				action:     o.cancelShipping,
+				compensate: o.rebookShipments,
+			},
+			{
+				name:   "refund-payment",
+				action: o.refundOrder,
+			},
+			{
+				name:   "cancel-order",
+				action: o.cancelOrder,
+			},
+		},
+	}
+}
+
+func (o *Orchestrator) checkCancellable(saga *Saga) error {
+	_, err := o.loadUndispatchedOrder(saga, ErrOrderNotCancellable)
+	return err
+}
+
+// refundOrder refunds everything paid for the order, if anything was.
+func (o *Orchestrator) refundOrder(saga *Saga) error {
+	if err := o.refundPayment(saga, 0); err != nil && !errors.Is(err, errNoPayment) {
+		return err
+	}
+	return nil
+}
+
+// loadUndispatchedOrder fetches a pending order and records its active
+// outbound shipments on the saga. Orders in any other state, or with a
+// shipment that has been picked up, are refused with notAllowed.
+func (o *Orchestrator) loadUndispatchedOrder(saga *Saga, notAllowed error) (*models.OrderResponse, error) {
+	ctx := sagaContext(saga)
+	order, err := o.orders.GetOrder(ctx, saga.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.OrderStatusPending {
+		return nil, fmt.Errorf("%w: order is %s", notAllowed, order.Status)
+	}
+
+	shipments, err := o.shipping.ListOrderShippings(ctx, saga.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	saga.Shipments = nil
+	for _, shipment := range shipments {
+		if shipment.Kind == models.ShipmentKindReturn || shipment.Status == models.ShippingStatusCancelled {
+			continue
+		}
+		if shippedStatuses[shipment.Status] {
+			return nil, fmt.Errorf("%w: %w", notAllowed, ErrOrderAlreadyShipped)
+		}
+		saga.Shipments = append(saga.Shipments, shipment)
+	}
+
+	return order, nil
+}
+
+// rebookShipments books the saga's recorded shipments again after they
+// were cancelled.
+func (o *Orchestrator) rebookShipments(saga *Saga) error {
+	ctx := sagaContext(saga)
+	for _, shipment := range saga.Shipments {
+		if _, err := o.shipping.StartShipping(ctx, rebookRequest(shipment)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebookRequest books shipment again as it was: same items, address,
+// weight, carrier and service level.
+func rebookRequest(shipment models.ShippingResponse) models.StartShippingRequest {
+	return models.StartShippingRequest{
+		OrderID:  shipment.OrderID,
+		Kind:     shipment.Kind,
+		Items:    shipment.Items,
+		Address:  shipment.Address,
+		WeightKg: shipment.WeightKg,
+		Carrier:  shipment.Carrier,
+		Service:  shipment.Service,
+	}
+}
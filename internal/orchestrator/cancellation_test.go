@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+func TestCancelOrderWhileCreateSagaParked(t *testing.T) {
+	f := newFakeServices()
+	f.paymentStatus = models.PaymentStatusPendingReview
+	o := newFakeOrchestrator(f)
+
+	created, err := o.CreateOrderSaga(context.Background(), testOrderRequest())
+	if err != nil || created.Status != SagaStatusAwaitingPaymentReview {
+		t.Fatalf("create status %s, error %v", created.Status, err)
+	}
+
+	// The parked saga keeps the order even if the order service's lock
+	// has lapsed in the meantime.
+	_, err = o.CancelOrderSaga(context.Background(), created.OrderID)
+	if !errors.Is(err, ErrOrderLocked) {
+		t.Fatalf("cancel error %v, want %v", err, ErrOrderLocked)
+	}
+	if status := f.orders[created.OrderID].Status; status != models.OrderStatusPending {
+		t.Errorf("order %s, want %s", status, models.OrderStatusPending)
+	}
+	if n := f.count("RefundPayment"); n != 0 {
+		t.Errorf("RefundPayment called %d times", n)
+	}
+}
+
+func TestCancelOrderSaga(t *testing.T) {
+	refused := apierror.Conflict("refused")
+
+	tests := []struct {
+		name          string
+		shipment      models.ShippingStatus
+		fail          map[string]error
+		wantErr       error
+		wantStatus    SagaStatus
+		wantOrder     models.OrderStatus
+		wantPayment   models.PaymentStatus
+		wantShipments []models.ShippingStatus
+	}{
+		{
+			name:          "pending order refunded and cancelled",
+			wantStatus:    SagaStatusCompleted,
+			wantOrder:     models.OrderStatusCancelled,
+			wantPayment:   models.PaymentStatusRefunded,
+			wantShipments: []models.ShippingStatus{models.ShippingStatusCancelled},
+		},
+		{
+			name:          "picked up order refused",
+			shipment:      models.ShippingStatusPickedUp,
+			wantErr:       ErrOrderNotCancellable,
+			wantStatus:    SagaStatusFailed,
+			wantOrder:     models.OrderStatusPending,
+			wantPayment:   models.PaymentStatusSuccess,
+			wantShipments: []models.ShippingStatus{models.ShippingStatusPickedUp},
+		},
+		{
+			name:          "failed refund rebooks the shipment",
+			fail:          map[string]error{"RefundPayment": refused},
+			wantStatus:    SagaStatusCompensated,
+			wantOrder:     models.OrderStatusPending,
+			wantPayment:   models.PaymentStatusSuccess,
+			wantShipments: []models.ShippingStatus{models.ShippingStatusCancelled, models.ShippingStatusLabelCreated},
+		},
+		{
+			name:          "failed order cancellation stalls after the refund",
+			fail:          map[string]error{"CancelOrder": refused},
+			wantStatus:    SagaStatusStalled,
+			wantOrder:     models.OrderStatusPending,
+			wantPayment:   models.PaymentStatusRefunded,
+			wantShipments: []models.ShippingStatus{models.ShippingStatusCancelled},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeServices()
+			o := newFakeOrchestrator(f)
+			created, err := o.CreateOrderSaga(context.Background(), testOrderRequest())
+			if err != nil {
+				t.Fatalf("create order: %v", err)
+			}
+			if tt.shipment != "" {
+				f.setShipmentStatus(created.ShippingID, tt.shipment)
+			}
+
+			f.fail = tt.fail
+			saga, err := o.CancelOrderSaga(context.Background(), created.OrderID)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+			if saga.Status != tt.wantStatus {
+				t.Errorf("status %s, want %s (error %v)", saga.Status, tt.wantStatus, err)
+			}
+			if got := f.orders[created.OrderID].Status; got != tt.wantOrder {
+				t.Errorf("order %s, want %s", got, tt.wantOrder)
+			}
+			if got := f.paymentsOf(created.OrderID); len(got) != 1 || got[0] != tt.wantPayment {
+				t.Errorf("payments %v, want %s", got, tt.wantPayment)
+			}
+
+			var shipments []models.ShippingStatus
+			for _, shipment := range f.shipments {
+				shipments = append(shipments, shipment.Status)
+			}
+			sort.Slice(shipments, func(i, j int) bool { return shipments[i] < shipments[j] })
+			if !reflect.DeepEqual(shipments, tt.wantShipments) {
+				t.Errorf("shipments %v, want %v", shipments, tt.wantShipments)
+			}
+		})
+	}
+}
@@ -0,0 +1,554 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/tracing"
+)
+
+// stepKind classifies a step per the saga model. Compensatable steps come
+// first and are undone when a later step fails. The pivot is the point of no
+// return: once it has succeeded the saga only moves forward, and the
+// retriable steps after it are retried rather than compensated.
+type stepKind int
+
+const (
+	compensatable stepKind = iota
+	pivot
+	retriable
+)
+
+func (k stepKind) String() string {
+	switch k {
+	case pivot:
+		return "pivot"
+	case retriable:
+		return "retriable"
+	default:
+		return "compensatable"
+	}
+}
+
+// sagaStep is one forward action of a saga together with the compensation
+// that undoes it. Steps without a compensation have nothing to undo.
+type sagaStep struct {
+	name       string
+	kind       stepKind
+	action     func(saga *Saga) error
+	compensate func(saga *Saga) error
+	// branches are the steps of a parallel group.
+	branches []sagaStep
+}
+
+// sagaDefinition is one version of a saga type's steps. A saga runs to the
+// end on the version it started with, so a definition whose steps change gets
+// a new version while the old one stays registered until no saga uses it.
+type sagaDefinition struct {
+	sagaType SagaType
+	version  int
+	steps    []sagaStep
+	// locksOrder makes the saga hold the order's semantic lock while it
+	// runs, so that no other saga changes the order at the same time.
+	locksOrder bool
+}
+
+// validate checks that the steps follow the saga model: any number of
+// compensatable steps, then at most one pivot, then only retriable steps.
+// Pivot and retriable steps are never compensated, so they may not define a
+// compensation, and parallel groups may only hold compensatable branches.
+func (d sagaDefinition) validate() error {
+	seen := make(map[string]bool)
+	pivotSeen := false
+
+	for _, step := range d.steps {
+		if seen[step.name] {
+			return fmt.Errorf("%s saga: duplicate step %s", d.sagaType, step.name)
+		}
+		seen[step.name] = true
+
+		switch step.kind {
+		case compensatable:
+			if pivotSeen {
+				return fmt.Errorf("%s saga: compensatable step %s follows the pivot", d.sagaType, step.name)
+			}
+		case pivot:
+			if pivotSeen {
+				return fmt.Errorf("%s saga: step %s is a second pivot", d.sagaType, step.name)
+			}
+			pivotSeen = true
+		case retriable:
+			if !pivotSeen {
+				return fmt.Errorf("%s saga: retriable step %s precedes the pivot", d.sagaType, step.name)
+			}
+		}
+
+		if step.kind != compensatable && step.compensate != nil {
+			return fmt.Errorf("%s saga: %s step %s cannot have a compensation", d.sagaType, step.kind, step.name)
+		}
+		for _, branch := range step.branches {
+			if branch.kind != compensatable {
+				return fmt.Errorf("%s saga: parallel step %s has %s branch %s", d.sagaType, step.name, branch.kind, branch.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d sagaDefinition) step(name string) (sagaStep, bool) {
+	for _, step := range d.steps {
+		if step.name == name {
+			return step, true
+		}
+	}
+	return sagaStep{}, false
+}
+
+// parallel groups branches into a single step whose actions run
+// concurrently. The group succeeds only if every branch does: when any
+// branch fails, the branches that succeeded are compensated before the group
+// fails. Compensating the group compensates every branch. A branch that
+// parks the saga does so once the whole group has succeeded. Branches run
+// on the same saga, so they must not write the same fields.
+func parallel(name string, branches ...sagaStep) sagaStep {
+	return sagaStep{
+		name:     name,
+		branches: branches,
+		action: func(saga *Saga) error {
+			errs := make([]error, len(branches))
+			var wg sync.WaitGroup
+			for i, branch := range branches {
+				wg.Add(1)
+				go func(i int, branch sagaStep) {
+					defer wg.Done()
+					errs[i] = branch.action(saga)
+				}(i, branch)
+			}
+			wg.Wait()
+
+			var (
+				failed    []error
+				succeeded []sagaStep
+				await     error
+			)
+			for i, err := range errs {
+				var awaitErr *awaitError
+				switch {
+				case err == nil:
+					succeeded = append(succeeded, branches[i])
+				case errors.As(err, &awaitErr):
+					succeeded = append(succeeded, branches[i])
+					await = err
+				default:
+					failed = append(failed, fmt.Errorf("%s: %w", branches[i].name, err))
+				}
+			}
+
+			if len(failed) > 0 {
+				if err := compensateBranches(saga, succeeded); err != nil {
+					saga.logger.Warn("Failed to compensate step", "step", name, "error", err)
+					saga.CompensationErrors = append(saga.CompensationErrors, fmt.Sprintf("%s: %v", name, err))
+				}
+				return errors.Join(failed...)
+			}
+			return await
+		},
+		compensate: func(saga *Saga) error {
+			return compensateBranches(saga, branches)
+		},
+	}
+}
+
+// pivotGroup is a parallel group that is the saga's pivot. Like any pivot it
+// is never compensated once the saga has moved past it, but its branches are
+// undone when the group fails, or when the saga is turned down while a
+// branch has it parked.
+func pivotGroup(name string, branches ...sagaStep) sagaStep {
+	step := parallel(name, branches...)
+	step.kind = pivot
+	step.compensate = nil
+	return step
+}
+
+// compensateBranches runs the compensations of branches concurrently and
+// joins their errors.
+func compensateBranches(saga *Saga, branches []sagaStep) error {
+	errs := make([]error, len(branches))
+	var wg sync.WaitGroup
+	for i, branch := range branches {
+		if branch.compensate == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, branch sagaStep) {
+			defer wg.Done()
+			if err := branch.compensate(saga); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", branch.name, err)
+			}
+		}(i, branch)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// awaitError is returned by a step that succeeded but must wait for an
+// external event before the saga can continue.
+type awaitError struct {
+	status SagaStatus
+}
+
+func (e *awaitError) Error() string {
+	return fmt.Sprintf("saga is %s", e.status)
+}
+
+func awaitEvent(status SagaStatus) error {
+	return &awaitError{status: status}
+}
+
+var sagaTypes = []SagaType{SagaTypeCreateOrder, SagaTypeReturn, SagaTypeCancelOrder, SagaTypeModifyOrder}
+
+// registeredDefinitions lists every definition version the orchestrator can
+// run. Keep old versions here until the sagas started on them have ended.
+func (o *Orchestrator) registeredDefinitions() []sagaDefinition {
+	return []sagaDefinition{
+		o.createOrderDefinitionV1(),
+		o.createOrderDefinition(),
+		o.returnDefinitionV1(),
+		o.returnDefinition(),
+		o.cancelOrderDefinitionV1(),
+		o.cancelOrderDefinition(),
+		o.modifyOrderDefinitionV1(),
+		o.modifyOrderDefinition(),
+	}
+}
+
+// ValidateDefinitions checks every registered saga definition against the
+// saga model. It is meant to be called once at startup.
+func (o *Orchestrator) ValidateDefinitions() error {
+	registered := make(map[SagaType]map[int]bool)
+	for _, def := range o.definitions {
+		if def.version < 1 {
+			return fmt.Errorf("%s saga: invalid version %d", def.sagaType, def.version)
+		}
+		if registered[def.sagaType][def.version] {
+			return fmt.Errorf("%s saga: version %d registered twice", def.sagaType, def.version)
+		}
+		if registered[def.sagaType] == nil {
+			registered[def.sagaType] = make(map[int]bool)
+		}
+		registered[def.sagaType][def.version] = true
+
+		if err := def.validate(); err != nil {
+			return fmt.Errorf("%w (version %d)", err, def.version)
+		}
+	}
+
+	for _, sagaType := range sagaTypes {
+		if len(registered[sagaType]) == 0 {
+			return fmt.Errorf("%s saga: no definition registered", sagaType)
+		}
+	}
+	return nil
+}
+
+// latestDefinition is the version new sagas of the type start on.
+func (o *Orchestrator) latestDefinition(sagaType SagaType) sagaDefinition {
+	var latest sagaDefinition
+	for _, def := range o.definitions {
+		if def.sagaType == sagaType && def.version > latest.version {
+			latest = def
+		}
+	}
+	return latest
+}
+
+// definition is the version the saga started on.
+func (o *Orchestrator) definition(saga *Saga) (sagaDefinition, error) {
+	for _, def := range o.definitions {
+		if def.sagaType == saga.Type && def.version == saga.DefinitionVersion {
+			return def, nil
+		}
+	}
+	return sagaDefinition{}, fmt.Errorf("%w: %s version %d", ErrDefinitionNotFound, saga.Type, saga.DefinitionVersion)
+}
+
+// startSaga creates a saga on the latest definition of its type.
+func (o *Orchestrator) startSaga(sagaType SagaType) *Saga {
+	saga := newSaga(sagaType)
+	saga.DefinitionVersion = o.latestDefinition(sagaType).version
+	return saga
+}
+
+// start runs a new saga, unless the orchestrator is shutting down. The
+// saga's trace continues the one in ctx, normally the request starting it.
+func (o *Orchestrator) start(ctx context.Context, saga *Saga) (_ *Saga, err error) {
+	o.metrics.started.Inc(string(saga.Type))
+	_, span := o.tracer.Start(ctx, "saga "+string(saga.Type))
+	o.beginRun(ctx, saga, span)
+	saga.logger.Info("Saga started", "definition_version", saga.DefinitionVersion)
+	defer func() { endRun(saga, err) }()
+
+	if !o.enter(true) {
+		return o.endSaga(saga, SagaStatusFailed, ErrShuttingDown)
+	}
+	defer o.leave()
+
+	return o.run(saga)
+}
+
+// run executes the saga's remaining steps, holding the order lock if the
+// definition asks for it until the saga ends. A failing step before or at the
+// pivot triggers compensation of every completed step, while a retriable step
+// that keeps failing stalls the saga until it is retried. A step that returns
+// awaitEvent parks the saga until it is resumed.
+func (o *Orchestrator) run(saga *Saga) (*Saga, error) {
+	def, err := o.definition(saga)
+	if err != nil {
+		return o.endSaga(saga, SagaStatusFailed, err)
+	}
+
+	// The lock is taken again on resume since it may have expired while
+	// the saga was parked.
+	if def.locksOrder && saga.OrderID != "" {
+		if err := o.lockOrder(saga); err != nil {
+			saga.FailedStep = "lock-order"
+			return o.compensate(saga, fmt.Errorf("lock-order failed: %w", err))
+		}
+	}
+
+	for saga.CurrentStep < len(def.steps) {
+		step := def.steps[saga.CurrentStep]
+
+		// Past the drain deadline: park the saga where it is.
+		if o.interrupted.Load() {
+			saga.FailedStep = step.name
+			return o.endSaga(saga, SagaStatusStalled, fmt.Errorf("%w: stopped before %s", ErrShuttingDown, step.name))
+		}
+
+		compensationErrors := len(saga.CompensationErrors)
+		start := time.Now()
+		endSpan := o.startSpan(saga, "step "+step.name, step)
+		err := o.runStep(saga, step)
+		var await *awaitError
+		endSpan(err)
+		o.observeStep(saga, step, time.Since(start), err)
+		// A failed parallel group compensates its own branches.
+		if failures := len(saga.CompensationErrors) - compensationErrors; failures > 0 {
+			o.metrics.compensationFailures.Add(float64(failures), string(saga.Type), step.name)
+		}
+
+		if err != nil && !errors.As(err, &await) {
+			saga.FailedStep = step.name
+			if step.kind == retriable {
+				return o.endSaga(saga, SagaStatusStalled, fmt.Errorf("%s failed: %w", step.name, err))
+			}
+			return o.compensate(saga, fmt.Errorf("%s failed: %w", step.name, err))
+		}
+
+		saga.CompletedSteps = append(saga.CompletedSteps, step.name)
+		saga.CurrentStep++
+
+		if await != nil {
+			saga.Status = await.status
+			o.sagas.save(saga)
+			saga.logger.Info("Saga waiting", "status", saga.Status, "step", step.name)
+			return saga, nil
+		}
+		o.sagas.save(saga)
+	}
+
+	o.unlockOrder(saga)
+	saga.Status = SagaStatusCompleted
+	o.sagas.save(saga)
+	o.metrics.completed.Inc(string(saga.Type))
+	saga.logger.Info("Saga completed")
+	return saga, nil
+}
+
+func (o *Orchestrator) observeStep(saga *Saga, step sagaStep, elapsed time.Duration, err error) {
+	var await *awaitError
+	outcome := "success"
+	switch {
+	case errors.As(err, &await):
+		outcome = "awaiting"
+	case err != nil:
+		outcome = "failure"
+	}
+	o.metrics.stepDuration.Observe(elapsed.Seconds(), string(saga.Type), step.name, outcome)
+}
+
+// runStep runs the step's action, attempting it up to o.retryAttempts times
+// with doubling backoff while shouldRetry allows.
+func (o *Orchestrator) runStep(saga *Saga, step sagaStep) error {
+	err := step.action(saga)
+
+	backoff := o.retryBackoff
+	for attempt := 1; attempt < o.retryAttempts && err != nil && shouldRetry(step, err) && !o.interrupted.Load(); attempt++ {
+		saga.logger.Warn("Retrying step", "step", step.name, "backoff", backoff, "error", err)
+		time.Sleep(backoff)
+		backoff *= 2
+		err = step.action(saga)
+	}
+	return err
+}
+
+// shouldRetry decides between retrying a failed step and giving up on it,
+// which compensates or stalls the saga. A service marking its error
+// retryable vouches that repeating the request is safe, so any step is
+// retried. Retriable steps are idempotent and are also retried on other
+// server errors and transport errors, which leave it open whether the
+// request took effect.
+func shouldRetry(step sagaStep, err error) bool {
+	var (
+		await  *awaitError
+		apiErr *apierror.Error
+	)
+	if errors.As(err, &await) {
+		return false
+	}
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable || step.kind == retriable && apiErr.Status >= http.StatusInternalServerError
+	}
+	return step.kind == retriable
+}
+
+// parked returns the saga named sagaID when it is parked awaiting, for the
+// resumes that check the event it waits for before claiming it.
+func (o *Orchestrator) parked(sagaID string, awaiting SagaStatus) (Saga, error) {
+	saga, exists := o.sagas.get(sagaID)
+	if !exists {
+		return Saga{}, ErrSagaNotFound
+	}
+	if saga.Status != awaiting {
+		return Saga{}, fmt.Errorf("%w: saga is %s", ErrSagaNotResumable, saga.Status)
+	}
+	return saga, nil
+}
+
+// resume continues a saga parked in the awaiting status. When proceed is
+// false the saga is compensated with reason instead.
+func (o *Orchestrator) resume(ctx context.Context, sagaID string, awaiting SagaStatus, proceed bool, reason error) (_ *Saga, err error) {
+	claimed, err := o.sagas.claim(sagaID, awaiting)
+	if err != nil {
+		return nil, err
+	}
+	saga := &claimed
+
+	// Resumes continue the saga's trace rather than the request's, so that
+	// the whole saga reads as one trace.
+	span := o.tracer.StartWithParent(saga.runSpan.Context(), "saga "+string(saga.Type)+" resume")
+	o.beginRun(ctx, saga, span)
+	defer func() { endRun(saga, err) }()
+	saga.logger.Info("Saga resumed", "awaiting", awaiting, "proceed", proceed)
+
+	o.enter(false)
+	defer o.leave()
+
+	if !proceed {
+		// Blame the step that was waiting on the decision.
+		if n := len(saga.CompletedSteps); n > 0 {
+			saga.FailedStep = saga.CompletedSteps[n-1]
+		}
+		return o.compensate(saga, reason)
+	}
+	return o.run(saga)
+}
+
+// compensate undoes the completed steps in reverse order. Compensation
+// failures are recorded on the saga but do not stop the remaining ones.
+func (o *Orchestrator) compensate(saga *Saga, cause error) (*Saga, error) {
+	def, err := o.definition(saga)
+	if err != nil {
+		return o.endSaga(saga, SagaStatusFailed, errors.Join(cause, err))
+	}
+	status := SagaStatusFailed
+
+	for i := len(saga.CompletedSteps) - 1; i >= 0; i-- {
+		step, ok := def.step(saga.CompletedSteps[i])
+		if !ok {
+			continue
+		}
+		undo := step.compensate
+		// A pivot that parked the saga has not passed the point of no
+		// return until it is resumed, so turning it down fails it.
+		if step.kind == pivot && step.name == saga.FailedStep && len(step.branches) > 0 {
+			branches := step.branches
+			undo = func(saga *Saga) error { return compensateBranches(saga, branches) }
+		}
+		if undo == nil {
+			continue
+		}
+
+		status = SagaStatusCompensated
+		endSpan := o.startSpan(saga, "compensate "+step.name, step)
+		err := undo(saga)
+		endSpan(err)
+		if err != nil {
+			o.metrics.compensationFailures.Inc(string(saga.Type), step.name)
+			saga.logger.Warn("Failed to compensate step", "step", step.name, "error", err)
+			saga.CompensationErrors = append(saga.CompensationErrors, fmt.Sprintf("%s: %v", step.name, err))
+		}
+	}
+
+	o.unlockOrder(saga)
+	return o.endSaga(saga, status, cause)
+}
+
+// beginRun makes span the saga's run span and ties the saga's logging and
+// requests to the request in ctx that set it going.
+func (o *Orchestrator) beginRun(ctx context.Context, saga *Saga, span *tracing.Span) {
+	saga.requestID = logging.RequestID(ctx)
+	saga.logger = logging.FromContext(ctx).With(
+		"saga_id", saga.ID,
+		"saga_type", saga.Type,
+		"trace_id", span.TraceID,
+	)
+
+	span.SetAttribute("saga.id", saga.ID)
+	span.SetAttribute("saga.type", string(saga.Type))
+	saga.TraceID = span.TraceID
+	saga.runSpan = span
+	saga.span = span
+}
+
+func endRun(saga *Saga, err error) {
+	saga.runSpan.SetAttribute("saga.status", string(saga.Status))
+	saga.runSpan.Finish(err)
+}
+
+// startSpan starts a span for a step or compensation of the saga, which
+// requests made for the saga continue until the returned func ends it. A
+// step parking the saga has not failed.
+func (o *Orchestrator) startSpan(saga *Saga, name string, step sagaStep) func(error) {
+	span := o.tracer.StartWithParent(saga.runSpan.Context(), name)
+	span.SetAttribute("saga.id", saga.ID)
+	span.SetAttribute("step.kind", step.kind.String())
+	saga.span = span
+
+	return func(err error) {
+		var await *awaitError
+		if errors.As(err, &await) {
+			span.SetAttribute("saga.awaiting", string(await.status))
+			err = nil
+		}
+		span.Finish(err)
+		saga.span = saga.runSpan
+	}
+}
+
+func (o *Orchestrator) endSaga(saga *Saga, status SagaStatus, err error) (*Saga, error) {
+	saga.Status = status
+	saga.Error = err.Error()
+	o.sagas.save(saga)
+	o.metrics.sagaEnded(saga, err)
+	saga.logger.Warn("Saga ended", "status", status, "failed_step", saga.FailedStep, "error", err)
+	return saga, err
+}
@@ -0,0 +1,407 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/metrics"
+	"saga-order-system/internal/tracing"
+)
+
+var (
+	errStep      = errors.New("step failed")
+	errRetryable = &apierror.Error{Status: http.StatusServiceUnavailable, Code: apierror.CodeUnavailable, Retryable: true}
+)
+
+// recorder fakes the actions and compensations of a saga's steps, counting
+// the attempts of each action and recording the compensations in order.
+type recorder struct {
+	mu       sync.Mutex
+	attempts map[string]int
+	undone   []string
+}
+
+func newRecorder() *recorder {
+	return &recorder{attempts: make(map[string]int)}
+}
+
+// action fails with err on its first failures attempts, or on every attempt
+// when failures is negative.
+func (r *recorder) action(name string, err error, failures int) func(*Saga) error {
+	return func(*Saga) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.attempts[name]++
+		if failures < 0 || r.attempts[name] <= failures {
+			return err
+		}
+		return nil
+	}
+}
+
+func (r *recorder) compensate(name string) func(*Saga) error {
+	return func(*Saga) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.undone = append(r.undone, name)
+		return nil
+	}
+}
+
+// step is a compensatable step that always succeeds.
+func (r *recorder) step(name string) sagaStep {
+	return sagaStep{name: name, action: r.action(name, nil, 0), compensate: r.compensate(name)}
+}
+
+func (r *recorder) failing(step sagaStep, err error, failures int) sagaStep {
+	step.action = r.action(step.name, err, failures)
+	return step
+}
+
+func newTestOrchestrator(defs ...sagaDefinition) *Orchestrator {
+	sagas, _ := newSagaStore("")
+	return &Orchestrator{
+		sagas:         sagas,
+		retryAttempts: 3,
+		definitions:   defs,
+		metrics:       newSagaMetrics(metrics.NewRegistry()),
+		tracer:        tracing.NewTracer("test", nil),
+	}
+}
+
+func noop(*Saga) error { return nil }
+
+func TestDefinitionValidate(t *testing.T) {
+	step := func(name string, kind stepKind) sagaStep {
+		s := sagaStep{name: name, kind: kind, action: noop}
+		if kind == compensatable {
+			s.compensate = noop
+		}
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		steps   []sagaStep
+		wantErr string
+	}{
+		{
+			name:  "compensatable, pivot, retriable",
+			steps: []sagaStep{step("a", compensatable), step("b", pivot), step("c", retriable)},
+		},
+		{
+			name:  "compensatable only",
+			steps: []sagaStep{step("a", compensatable), step("b", compensatable)},
+		},
+		{
+			name:  "pivot group",
+			steps: []sagaStep{step("a", compensatable), pivotGroup("b", step("b1", compensatable), step("b2", compensatable)), step("c", retriable)},
+		},
+		{
+			name:    "duplicate step",
+			steps:   []sagaStep{step("a", compensatable), step("a", compensatable)},
+			wantErr: "duplicate step a",
+		},
+		{
+			name:    "compensatable after pivot",
+			steps:   []sagaStep{step("a", pivot), step("b", compensatable)},
+			wantErr: "compensatable step b follows the pivot",
+		},
+		{
+			name:    "second pivot",
+			steps:   []sagaStep{step("a", pivot), step("b", pivot)},
+			wantErr: "step b is a second pivot",
+		},
+		{
+			name:    "retriable before pivot",
+			steps:   []sagaStep{step("a", retriable), step("b", pivot)},
+			wantErr: "retriable step a precedes the pivot",
+		},
+		{
+			name:    "pivot with compensation",
+			steps:   []sagaStep{{name: "a", kind: pivot, action: noop, compensate: noop}},
+			wantErr: "pivot step a cannot have a compensation",
+		},
+		{
+			name:    "retriable with compensation",
+			steps:   []sagaStep{step("a", pivot), {name: "b", kind: retriable, action: noop, compensate: noop}},
+			wantErr: "retriable step b cannot have a compensation",
+		},
+		{
+			name:    "parallel group with a retriable branch",
+			steps:   []sagaStep{parallel("a", step("a1", compensatable), step("a2", retriable))},
+			wantErr: "parallel step a has retriable branch a2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sagaDefinition{sagaType: SagaTypeCreateOrder, version: 1, steps: tt.steps}.validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("validate() = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		steps        func(r *recorder) []sagaStep
+		wantStatus   SagaStatus
+		wantFailed   string
+		wantUndone   []string
+		wantAttempts map[string]int
+	}{
+		{
+			name: "completes",
+			steps: func(r *recorder) []sagaStep {
+				return []sagaStep{r.step("a"), r.step("b")}
+			},
+			wantStatus:   SagaStatusCompleted,
+			wantAttempts: map[string]int{"a": 1, "b": 1},
+		},
+		{
+			name: "failed step compensates the completed ones in reverse",
+			steps: func(r *recorder) []sagaStep {
+				return []sagaStep{r.step("a"), r.step("b"), r.failing(r.step("c"), errStep, -1)}
+			},
+			wantStatus:   SagaStatusCompensated,
+			wantFailed:   "c",
+			wantUndone:   []string{"b", "a"},
+			wantAttempts: map[string]int{"a": 1, "b": 1, "c": 1},
+		},
+		{
+			name: "retryable error is retried on a compensatable step",
+			steps: func(r *recorder) []sagaStep {
+				return []sagaStep{r.failing(r.step("a"), errRetryable, 2)}
+			},
+			wantStatus:   SagaStatusCompleted,
+			wantAttempts: map[string]int{"a": 3},
+		},
+		{
+			name: "failed pivot compensates the steps before it",
+			steps: func(r *recorder) []sagaStep {
+				p := r.failing(sagaStep{name: "p", kind: pivot}, errStep, -1)
+				return []sagaStep{r.step("a"), p}
+			},
+			wantStatus:   SagaStatusCompensated,
+			wantFailed:   "p",
+			wantUndone:   []string{"a"},
+			wantAttempts: map[string]int{"a": 1, "p": 1},
+		},
+		{
+			name: "retriable step is retried on any error",
+			steps: func(r *recorder) []sagaStep {
+				p := sagaStep{name: "p", kind: pivot, action: r.action("p", nil, 0)}
+				return []sagaStep{r.step("a"), p, r.failing(sagaStep{name: "s", kind: retriable}, errStep, 2)}
+			},
+			wantStatus:   SagaStatusCompleted,
+			wantAttempts: map[string]int{"a": 1, "p": 1, "s": 3},
+		},
+		{
+			name: "retriable step that keeps failing stalls without compensating",
+			steps: func(r *recorder) []sagaStep {
+				p := sagaStep{name: "p", kind: pivot, action: r.action("p", nil, 0)}
+				return []sagaStep{r.step("a"), p, r.failing(sagaStep{name: "s", kind: retriable}, errStep, -1)}
+			},
+			wantStatus:   SagaStatusStalled,
+			wantFailed:   "s",
+			wantAttempts: map[string]int{"a": 1, "p": 1, "s": 3},
+		},
+		{
+			name: "failed parallel branch compensates the branches that succeeded",
+			steps: func(r *recorder) []sagaStep {
+				return []sagaStep{r.step("a"), parallel("g", r.step("g1"), r.failing(r.step("g2"), errStep, -1))}
+			},
+			wantStatus:   SagaStatusCompensated,
+			wantFailed:   "g",
+			wantUndone:   []string{"g1", "a"},
+			wantAttempts: map[string]int{"a": 1, "g1": 1, "g2": 1},
+		},
+		{
+			name: "completed parallel group compensates every branch",
+			steps: func(r *recorder) []sagaStep {
+				return []sagaStep{parallel("g", r.step("g1")), r.failing(r.step("b"), errStep, -1)}
+			},
+			wantStatus:   SagaStatusCompensated,
+			wantFailed:   "b",
+			wantUndone:   []string{"g1"},
+			wantAttempts: map[string]int{"g1": 1, "b": 1},
+		},
+		{
+			name: "failed pivot group compensates its branches that succeeded",
+			steps: func(r *recorder) []sagaStep {
+				return []sagaStep{r.step("a"), pivotGroup("g", r.step("g1"), r.failing(r.step("g2"), errStep, -1))}
+			},
+			wantStatus:   SagaStatusCompensated,
+			wantFailed:   "g",
+			wantUndone:   []string{"g1", "a"},
+			wantAttempts: map[string]int{"a": 1, "g1": 1, "g2": 1},
+		},
+		{
+			name: "completed pivot group is not compensated",
+			steps: func(r *recorder) []sagaStep {
+				return []sagaStep{r.step("a"), pivotGroup("g", r.step("g1")), r.failing(sagaStep{name: "s", kind: retriable}, errStep, -1)}
+			},
+			wantStatus:   SagaStatusStalled,
+			wantFailed:   "s",
+			wantAttempts: map[string]int{"a": 1, "g1": 1, "s": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRecorder()
+			def := sagaDefinition{sagaType: SagaTypeCreateOrder, version: 1, steps: tt.steps(r)}
+			if err := def.validate(); err != nil {
+				t.Fatalf("invalid definition: %v", err)
+			}
+			o := newTestOrchestrator(def)
+
+			saga, _ := o.start(context.Background(), o.startSaga(SagaTypeCreateOrder))
+
+			if saga.Status != tt.wantStatus || saga.FailedStep != tt.wantFailed {
+				t.Errorf("status %s, failed step %q; want %s, %q", saga.Status, saga.FailedStep, tt.wantStatus, tt.wantFailed)
+			}
+			if !reflect.DeepEqual(r.undone, tt.wantUndone) {
+				t.Errorf("compensated %v, want %v", r.undone, tt.wantUndone)
+			}
+			if !reflect.DeepEqual(r.attempts, tt.wantAttempts) {
+				t.Errorf("attempts %v, want %v", r.attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestResumeParkedPivotGroup(t *testing.T) {
+	tests := []struct {
+		name       string
+		proceed    bool
+		wantStatus SagaStatus
+		wantUndone []string
+	}{
+		{name: "approved", proceed: true, wantStatus: SagaStatusCompleted},
+		{name: "rejected", proceed: false, wantStatus: SagaStatusCompensated, wantUndone: []string{"g1", "g2", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRecorder()
+			review := r.step("g2")
+			review.action = func(*Saga) error { return awaitEvent(SagaStatusAwaitingPaymentReview) }
+			def := sagaDefinition{
+				sagaType: SagaTypeCreateOrder,
+				version:  1,
+				steps: []sagaStep{
+					r.step("a"),
+					pivotGroup("g", r.step("g1"), review),
+					{name: "s", kind: retriable, action: r.action("s", nil, 0)},
+				},
+			}
+			o := newTestOrchestrator(def)
+
+			saga, err := o.start(context.Background(), o.startSaga(SagaTypeCreateOrder))
+			if err != nil || saga.Status != SagaStatusAwaitingPaymentReview {
+				t.Fatalf("start: status %s, error %v", saga.Status, err)
+			}
+
+			saga, _ = o.resume(context.Background(), saga.ID, SagaStatusAwaitingPaymentReview, tt.proceed, errors.New("rejected"))
+			if saga.Status != tt.wantStatus {
+				t.Errorf("status %s, want %s", saga.Status, tt.wantStatus)
+			}
+			// The branches are compensated concurrently.
+			if len(r.undone) == 3 && r.undone[0] == "g2" {
+				r.undone[0], r.undone[1] = r.undone[1], r.undone[0]
+			}
+			if !reflect.DeepEqual(r.undone, tt.wantUndone) {
+				t.Errorf("compensated %v, want %v", r.undone, tt.wantUndone)
+			}
+		})
+	}
+}
+
+func TestDefinitionVersions(t *testing.T) {
+	v1 := sagaDefinition{sagaType: SagaTypeCreateOrder, version: 1, steps: []sagaStep{{name: "a", action: noop}}}
+	v2 := sagaDefinition{sagaType: SagaTypeCreateOrder, version: 2, steps: []sagaStep{{name: "b", action: noop}}}
+	ret := sagaDefinition{sagaType: SagaTypeReturn, version: 3}
+	o := newTestOrchestrator(v2, ret, v1)
+
+	if got := o.latestDefinition(SagaTypeCreateOrder).version; got != 2 {
+		t.Errorf("latest create-order version = %d, want 2", got)
+	}
+
+	tests := []struct {
+		sagaType SagaType
+		version  int
+		wantStep string
+		wantErr  error
+	}{
+		{SagaTypeCreateOrder, 1, "a", nil},
+		{SagaTypeCreateOrder, 2, "b", nil},
+		{SagaTypeCreateOrder, 3, "", ErrDefinitionNotFound},
+		{SagaTypeReturn, 1, "", ErrDefinitionNotFound},
+	}
+	for _, tt := range tests {
+		saga := newSaga(tt.sagaType)
+		saga.DefinitionVersion = tt.version
+		def, err := o.definition(saga)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("definition(%s v%d) error = %v, want %v", tt.sagaType, tt.version, err, tt.wantErr)
+			continue
+		}
+		if err == nil && def.steps[0].name != tt.wantStep {
+			t.Errorf("definition(%s v%d) runs %s, want %s", tt.sagaType, tt.version, def.steps[0].name, tt.wantStep)
+		}
+	}
+}
+
+func TestValidateDefinitions(t *testing.T) {
+	registered := newTestOrchestrator().registeredDefinitions()
+	without := func(sagaType SagaType) []sagaDefinition {
+		var defs []sagaDefinition
+		for _, def := range registered {
+			if def.sagaType != sagaType {
+				defs = append(defs, def)
+			}
+		}
+		return defs
+	}
+	with := func(def sagaDefinition) []sagaDefinition {
+		return append(append([]sagaDefinition{}, registered...), def)
+	}
+	invalid := sagaDefinition{sagaType: SagaTypeReturn, version: 9, steps: []sagaStep{{name: "a", kind: retriable, action: noop}}}
+
+	tests := []struct {
+		name    string
+		defs    []sagaDefinition
+		wantErr string
+	}{
+		{name: "registered definitions", defs: registered},
+		{name: "invalid version", defs: append(without(SagaTypeReturn), sagaDefinition{sagaType: SagaTypeReturn}), wantErr: "invalid version 0"},
+		{name: "version registered twice", defs: with(registered[0]), wantErr: "registered twice"},
+		{name: "saga type missing", defs: without(SagaTypeReturn), wantErr: "no definition registered"},
+		{name: "invalid steps", defs: with(invalid), wantErr: "precedes the pivot (version 9)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestOrchestrator(tt.defs...).ValidateDefinitions()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("ValidateDefinitions() = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("ValidateDefinitions() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,430 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+// fakeServices stands in for the order, payment and shipping services. It
+// keeps just enough state for the sagas to run against, records every call
+// by method name, and fails the methods named in fail.
+type fakeServices struct {
+	mu    sync.Mutex
+	calls []string
+	fail  map[string]error
+	// paymentStatus is the status ProcessPayment gives new payments.
+	paymentStatus models.PaymentStatus
+
+	orders    map[string]models.OrderResponse
+	payments  map[string]models.PaymentResponse
+	shipments map[string]models.ShippingResponse
+	returns   map[string]models.Return
+	stock     map[string]int
+	reserved  map[string]bool
+}
+
+func newFakeServices() *fakeServices {
+	return &fakeServices{
+		fail:          make(map[string]error),
+		paymentStatus: models.PaymentStatusSuccess,
+		orders:        make(map[string]models.OrderResponse),
+		payments:      make(map[string]models.PaymentResponse),
+		shipments:     make(map[string]models.ShippingResponse),
+		returns:       make(map[string]models.Return),
+		stock:         make(map[string]int),
+		reserved:      make(map[string]bool),
+	}
+}
+
+// newFakeOrchestrator runs the registered saga definitions against f.
+func newFakeOrchestrator(f *fakeServices) *Orchestrator {
+	o := newTestOrchestrator()
+	o.orders, o.payments, o.shipping = f, f, f
+	o.definitions = o.registeredDefinitions()
+	return o
+}
+
+// call records a call to method and returns the error it should fail with.
+// Callers must hold f.mu.
+func (f *fakeServices) call(method string) error {
+	f.calls = append(f.calls, method)
+	return f.fail[method]
+}
+
+func (f *fakeServices) count(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, call := range f.calls {
+		if call == method {
+			n++
+		}
+	}
+	return n
+}
+
+func notFound(format string, args ...interface{}) error {
+	return apierror.NotFound(fmt.Sprintf(format, args...))
+}
+
+func (f *fakeServices) CreateOrder(ctx context.Context, req models.CreateOrderRequest) (*models.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CreateOrder"); err != nil {
+		return nil, err
+	}
+	order := models.OrderResponse{
+		ID:              uuid.New().String(),
+		UserID:          req.UserID,
+		Items:           req.Items,
+		ShippingAddress: req.ShippingAddress,
+		Status:          models.OrderStatusPending,
+	}
+	for _, item := range req.Items {
+		order.TotalPrice += item.Price * float64(item.Quantity)
+	}
+	f.orders[order.ID] = order
+	return &order, nil
+}
+
+func (f *fakeServices) GetOrder(ctx context.Context, orderID string) (*models.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("GetOrder"); err != nil {
+		return nil, err
+	}
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, notFound("Order %s not found", orderID)
+	}
+	return &order, nil
+}
+
+func (f *fakeServices) CancelOrder(ctx context.Context, orderID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CancelOrder"); err != nil {
+		return err
+	}
+	order, ok := f.orders[orderID]
+	if !ok {
+		return notFound("Order %s not found", orderID)
+	}
+	order.Status = models.OrderStatusCancelled
+	f.orders[orderID] = order
+	delete(f.reserved, orderID)
+	return nil
+}
+
+func (f *fakeServices) ModifyOrder(ctx context.Context, orderID string, req models.ModifyOrderRequest) (*models.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ModifyOrder"); err != nil {
+		return nil, err
+	}
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, notFound("Order %s not found", orderID)
+	}
+	if req.Items != nil {
+		order.Items = req.Items
+		order.TotalPrice = 0
+		for _, item := range req.Items {
+			order.TotalPrice += item.Price * float64(item.Quantity)
+		}
+	}
+	if req.ShippingAddress != nil {
+		order.ShippingAddress = *req.ShippingAddress
+	}
+	f.orders[orderID] = order
+	return &order, nil
+}
+
+func (f *fakeServices) LockOrder(ctx context.Context, orderID string, req models.LockOrderRequest) (*models.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("LockOrder"); err != nil {
+		return nil, err
+	}
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, notFound("Order %s not found", orderID)
+	}
+	if order.Status == models.OrderStatusCancelled {
+		return nil, apierror.Conflict(fmt.Sprintf("Order %s is cancelled and cannot be locked", orderID))
+	}
+	return &order, nil
+}
+
+func (f *fakeServices) UnlockOrder(ctx context.Context, orderID string, req models.UnlockOrderRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call("UnlockOrder")
+}
+
+func (f *fakeServices) ReserveStock(ctx context.Context, req models.ReserveStockRequest) (*models.InventoryReservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ReserveStock"); err != nil {
+		return nil, err
+	}
+	f.reserved[req.OrderID] = true
+	return &models.InventoryReservation{OrderID: req.OrderID, Items: req.Items}, nil
+}
+
+func (f *fakeServices) ReleaseStock(ctx context.Context, orderID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ReleaseStock"); err != nil {
+		return err
+	}
+	delete(f.reserved, orderID)
+	return nil
+}
+
+func (f *fakeServices) Restock(ctx context.Context, req models.StockAdjustmentRequest) ([]models.StockLevel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Restock"); err != nil {
+		return nil, err
+	}
+	var levels []models.StockLevel
+	for _, item := range req.Items {
+		f.stock[item.ProductID] += item.Quantity
+		levels = append(levels, models.StockLevel{ProductID: item.ProductID, Quantity: f.stock[item.ProductID]})
+	}
+	return levels, nil
+}
+
+func (f *fakeServices) DeductStock(ctx context.Context, req models.StockAdjustmentRequest) ([]models.StockLevel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("DeductStock"); err != nil {
+		return nil, err
+	}
+	var levels []models.StockLevel
+	for _, item := range req.Items {
+		f.stock[item.ProductID] -= item.Quantity
+		levels = append(levels, models.StockLevel{ProductID: item.ProductID, Quantity: f.stock[item.ProductID]})
+	}
+	return levels, nil
+}
+
+func (f *fakeServices) CreateReturn(ctx context.Context, req models.CreateReturnRequest) (*models.Return, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CreateReturn"); err != nil {
+		return nil, err
+	}
+	order, ok := f.orders[req.OrderID]
+	if !ok {
+		return nil, notFound("Order %s not found", req.OrderID)
+	}
+	ret := models.Return{
+		ID:            uuid.New().String(),
+		OrderID:       req.OrderID,
+		Items:         req.Items,
+		Status:        models.ReturnStatusOpen,
+		RefundAmount:  order.TotalPrice,
+		PickupAddress: order.ShippingAddress,
+	}
+	f.returns[ret.ID] = ret
+	return &ret, nil
+}
+
+func (f *fakeServices) moveReturn(method, returnID string, from, to models.ReturnStatus) (*models.Return, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(method); err != nil {
+		return nil, err
+	}
+	ret, ok := f.returns[returnID]
+	if !ok {
+		return nil, notFound("Return %s not found", returnID)
+	}
+	if ret.Status != from && ret.Status != to {
+		return nil, apierror.Conflict(fmt.Sprintf("Return %s is %s", returnID, ret.Status))
+	}
+	ret.Status = to
+	f.returns[returnID] = ret
+	return &ret, nil
+}
+
+func (f *fakeServices) ReceiveReturn(ctx context.Context, returnID string) (*models.Return, error) {
+	return f.moveReturn("ReceiveReturn", returnID, models.ReturnStatusOpen, models.ReturnStatusReceived)
+}
+
+func (f *fakeServices) CompleteReturn(ctx context.Context, returnID string) (*models.Return, error) {
+	return f.moveReturn("CompleteReturn", returnID, models.ReturnStatusReceived, models.ReturnStatusCompleted)
+}
+
+func (f *fakeServices) CancelReturn(ctx context.Context, returnID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CancelReturn"); err != nil {
+		return err
+	}
+	ret, ok := f.returns[returnID]
+	if !ok {
+		return notFound("Return %s not found", returnID)
+	}
+	if ret.Status == models.ReturnStatusCompleted {
+		return apierror.Conflict(fmt.Sprintf("Return %s is already completed", returnID))
+	}
+	ret.Status = models.ReturnStatusCancelled
+	f.returns[returnID] = ret
+	return nil
+}
+
+func (f *fakeServices) ProcessPayment(ctx context.Context, req models.ProcessPaymentRequest) (*models.PaymentResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ProcessPayment"); err != nil {
+		return nil, err
+	}
+	payment := models.PaymentResponse{
+		ID:      uuid.New().String(),
+		OrderID: req.OrderID,
+		Amount:  req.Amount,
+		Status:  f.paymentStatus,
+	}
+	f.payments[payment.ID] = payment
+	return &payment, nil
+}
+
+func (f *fakeServices) GetPayment(ctx context.Context, paymentID string) (*models.PaymentResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("GetPayment"); err != nil {
+		return nil, err
+	}
+	payment, exists := f.payments[paymentID]
+	if !exists {
+		return nil, notFound("payment %s not found", paymentID)
+	}
+	return &payment, nil
+}
+
+func (f *fakeServices) RefundPayment(ctx context.Context, req models.RefundPaymentRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RefundPayment"); err != nil {
+		return err
+	}
+	for id, payment := range f.payments {
+		if payment.OrderID != req.OrderID ||
+			payment.Status != models.PaymentStatusSuccess && payment.Status != models.PaymentStatusPartiallyRefunded {
+			continue
+		}
+		amount := req.Amount
+		if amount == 0 {
+			amount = payment.Amount - payment.RefundedAmount
+		}
+		payment.RefundedAmount += amount
+		payment.Status = models.PaymentStatusPartiallyRefunded
+		if payment.RefundedAmount >= payment.Amount {
+			payment.Status = models.PaymentStatusRefunded
+		}
+		f.payments[id] = payment
+		return nil
+	}
+	return notFound("Payment not found for the given order")
+}
+
+func (f *fakeServices) VoidPayment(ctx context.Context, req models.VoidPaymentRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("VoidPayment"); err != nil {
+		return err
+	}
+	for id, payment := range f.payments {
+		if payment.OrderID == req.OrderID && payment.Status == models.PaymentStatusPendingReview {
+			payment.Status = models.PaymentStatusVoided
+			f.payments[id] = payment
+		}
+	}
+	return nil
+}
+
+func (f *fakeServices) ValidateAddress(ctx context.Context, address models.Address) (*models.Address, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ValidateAddress"); err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (f *fakeServices) StartShipping(ctx context.Context, req models.StartShippingRequest) (*models.ShippingResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("StartShipping"); err != nil {
+		return nil, err
+	}
+	kind := req.Kind
+	if kind == "" {
+		kind = models.ShipmentKindOutbound
+	}
+	shipment := models.ShippingResponse{
+		ID:      uuid.New().String(),
+		OrderID: req.OrderID,
+		Kind:    kind,
+		Items:   req.Items,
+		Address: req.Address,
+		Status:  models.ShippingStatusLabelCreated,
+	}
+	f.shipments[shipment.ID] = shipment
+	return &shipment, nil
+}
+
+func (f *fakeServices) CancelShipping(ctx context.Context, req models.CancelShippingRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CancelShipping"); err != nil {
+		return err
+	}
+	for id, shipment := range f.shipments {
+		if shipment.OrderID != req.OrderID || req.ShippingID != "" && id != req.ShippingID {
+			continue
+		}
+		if req.ShippingID == "" && shipment.Kind == models.ShipmentKindReturn {
+			continue
+		}
+		shipment.Status = models.ShippingStatusCancelled
+		f.shipments[id] = shipment
+	}
+	return nil
+}
+
+func (f *fakeServices) ListOrderShippings(ctx context.Context, orderID string) ([]models.ShippingResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ListOrderShippings"); err != nil {
+		return nil, err
+	}
+	shipments := []models.ShippingResponse{}
+	for _, shipment := range f.shipments {
+		if shipment.OrderID == orderID {
+			shipments = append(shipments, shipment)
+		}
+	}
+	return shipments, nil
+}
+
+// paymentsOf returns the statuses of the order's payments.
+func (f *fakeServices) paymentsOf(orderID string) []models.PaymentStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var statuses []models.PaymentStatus
+	for _, payment := range f.payments {
+		if payment.OrderID == orderID {
+			statuses = append(statuses, payment.Status)
+		}
+	}
+	return statuses
+}
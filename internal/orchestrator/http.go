@@ -0,0 +1,17 @@
+package orchestrator
+
+import (
+	"context"
+
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/tracing"
+)
+
+// sagaContext returns the context for requests made on behalf of saga. The
+// clients tell the services which saga and request a call is for, so the
+// order service lets the saga holding an order's lock still change it, and
+// continue the saga's trace.
+func sagaContext(saga *Saga) context.Context {
+	ctx := logging.ContextWithIDs(context.Background(), saga.requestID, saga.ID)
+	return tracing.ContextWithSpan(ctx, saga.span)
+}
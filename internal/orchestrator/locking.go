@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+var ErrOrderLocked = errors.New("order is locked by another saga")
+
+// lockOrder takes the order service's semantic lock on the saga's order, or
+// refreshes it when the saga already holds it. Another saga holding the
+// lock makes this fail with ErrOrderLocked, including one still parked on
+// the order after the order service's lock has expired.
+func (o *Orchestrator) lockOrder(saga *Saga) error {
+	if holder, held := o.sagas.lockHolder(saga.OrderID, saga.ID); held {
+		return fmt.Errorf("%w: %s is held by saga %s", ErrOrderLocked, saga.OrderID, holder)
+	}
+
+	lockReq := models.LockOrderRequest{SagaID: saga.ID}
+	_, err := o.orders.LockOrder(sagaContext(saga), saga.OrderID, lockReq)
+	if apierror.HasStatus(err, http.StatusLocked) {
+		return fmt.Errorf("%w: %s", ErrOrderLocked, saga.OrderID)
+	}
+	if err != nil {
+		return err
+	}
+
+	saga.OrderLocked = true
+	return nil
+}
+
+// unlockOrder releases the saga's lock on its order. A lock that cannot be
+// released is only logged; it lapses on its own once it expires.
+func (o *Orchestrator) unlockOrder(saga *Saga) {
+	if !saga.OrderLocked {
+		return
+	}
+
+	unlockReq := models.UnlockOrderRequest{SagaID: saga.ID}
+	if err := o.orders.UnlockOrder(sagaContext(saga), saga.OrderID, unlockReq); err != nil {
+		saga.logger.Warn("Failed to unlock order", "order_id", saga.OrderID, "error", err)
+		return
+	}
+	saga.OrderLocked = false
+}
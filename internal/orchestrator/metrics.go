@@ -0,0 +1,118 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"saga-order-system/internal/metrics"
+)
+
+type sagaMetrics struct {
+	started              *metrics.CounterVec
+	completed            *metrics.CounterVec
+	compensated          *metrics.CounterVec
+	failed               *metrics.CounterVec
+	stalled              *metrics.CounterVec
+	stepDuration         *metrics.HistogramVec
+	compensationFailures *metrics.CounterVec
+	downstreamRequests   *metrics.CounterVec
+	downstreamDuration   *metrics.HistogramVec
+}
+
+func newSagaMetrics(registry *metrics.Registry) *sagaMetrics {
+	return &sagaMetrics{
+		started:              registry.Counter("sagas_started_total", "Sagas started.", "type"),
+		completed:            registry.Counter("sagas_completed_total", "Sagas completed.", "type"),
+		compensated:          registry.Counter("sagas_compensated_total", "Sagas rolled back by compensation, by the step that failed.", "type", "reason"),
+		failed:               registry.Counter("sagas_failed_total", "Sagas failed without anything to compensate, by the step that failed.", "type", "reason"),
+		stalled:              registry.Counter("sagas_stalled_total", "Sagas stalled on a retriable step, by that step.", "type", "reason"),
+		stepDuration:         registry.Histogram("saga_step_duration_seconds", "Time spent running a saga step, retries included.", metrics.DefaultBuckets, "type", "step", "outcome"),
+		compensationFailures: registry.Counter("saga_compensation_failures_total", "Compensations that failed.", "type", "step"),
+		downstreamRequests:   registry.Counter("downstream_requests_total", "Requests made to the services, by response status.", "service", "status"),
+		downstreamDuration:   registry.Histogram("downstream_request_duration_seconds", "Time spent on requests to the services.", metrics.DefaultBuckets, "service"),
+	}
+}
+
+// sagaEnded counts a saga that ended in a status other than COMPLETED.
+func (m *sagaMetrics) sagaEnded(saga *Saga, err error) {
+	reason := saga.FailedStep
+	switch {
+	case reason != "":
+	case errors.Is(err, ErrShuttingDown):
+		reason = "shutdown"
+	case errors.Is(err, ErrDefinitionNotFound):
+		reason = "definition"
+	default:
+		reason = "unknown"
+	}
+
+	switch saga.Status {
+	case SagaStatusCompensated:
+		m.compensated.Inc(string(saga.Type), reason)
+	case SagaStatusStalled:
+		m.stalled.Inc(string(saga.Type), reason)
+	default:
+		m.failed.Inc(string(saga.Type), reason)
+	}
+}
+
+// instrumentedTransport counts and times every request the orchestrator
+// makes, labelled by the service it went to.
+type instrumentedTransport struct {
+	next     http.RoundTripper
+	metrics  *sagaMetrics
+	services map[string]string
+}
+
+func (o *Orchestrator) instrumentTransport(next http.RoundTripper) http.RoundTripper {
+	services := make(map[string]string)
+	for name, baseURL := range map[string]string{
+		"order":    o.orderServiceURL,
+		"payment":  o.paymentServiceURL,
+		"shipping": o.shippingServiceURL,
+	} {
+		if u, err := url.Parse(baseURL); err == nil {
+			services[u.Host] = name
+		}
+	}
+
+	return &instrumentedTransport{next: next, metrics: o.metrics, services: services}
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	service, known := t.services[req.URL.Host]
+	if !known {
+		service = "other"
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	t.metrics.downstreamDuration.Observe(time.Since(start).Seconds(), service)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	t.metrics.downstreamRequests.Inc(service, status)
+
+	return resp, err
+}
+
+// instrumentCall counts and times every gRPC call made to service, as
+// instrumentedTransport does for HTTP requests, with the gRPC status code
+// as the status.
+func (o *Orchestrator) instrumentCall(service string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		o.metrics.downstreamDuration.Observe(time.Since(start).Seconds(), service)
+		o.metrics.downstreamRequests.Inc(service, status.Code(err).String())
+		return err
+	}
+}
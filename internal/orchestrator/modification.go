@@ -0,0 +1,293 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"saga-order-system/internal/models"
+)
+
+var ErrOrderNotModifiable = errors.New("order cannot be modified")
+
+// ModifyOrderRequest changes the items and/or delivery address of an order
+// before dispatch. PaymentMethodID or CardNumber pay for any increase in
+// the total.
+type ModifyOrderRequest struct {
+	Items           []models.OrderItem `json:"items"`
+	Address         *models.Address    `json:"address"`
+	PaymentMethodID string             `json:"payment_method_id"`
+	CardNumber      string             `json:"card_number"`
+}
+
+// ModifyOrderSaga updates a pending order, rebooks its shipment and charges
+// or refunds the difference in total. Any failure rolls the order and the
+// shipment back to how they were.
+func (o *Orchestrator) ModifyOrderSaga(ctx context.Context, orderID string, req ModifyOrderRequest) (*Saga, error) {
+	saga := o.startSaga(SagaTypeModifyOrder)
+	saga.OrderID = orderID
+	saga.Modification = &req
+	o.sagas.save(saga)
+
+	return o.start(ctx, saga)
+}
+
+// modifyOrderDefinition is version 2 of the modify saga, in which settling
+// the difference in total is the pivot.
+func (o *Orchestrator) modifyOrderDefinition() sagaDefinition {
+	return sagaDefinition{
+		sagaType:   SagaTypeModifyOrder,
+		version:    2,
+		locksOrder: true,
+		steps: []sagaStep{
+			{
+				name:   "check-order",
+				action: o.checkModifiable,
+			},
+			{
+				name:   "validate-address",
+				action: o.validateModifiedAddress,
+			},
+			{
+				name:       "update-order",
+				action:     o.updateOrder,
+				compensate: o.restoreOrder,
+			},
+			{
+				name:       "update-shipment",
+				action:     o.updateShipment,
+				compensate: o.restoreUpdatedShipments,
+			},
+			{
+				name:   "settle-payment",
+				kind:   pivot,
+				action: o.settleDifference,
+			},
+		},
+	}
+}
+
+// modifyOrderDefinitionV1 is the modify saga before settling the payment
+// was its pivot. Sagas started on it still finish on it.
+func (o *Orchestrator) modifyOrderDefinitionV1() sagaDefinition {
+	return sagaDefinition{
+		sagaType:   SagaTypeModifyOrder,
+		version:    1,
+		locksOrder: true,
+		steps: []sagaStep{
+			{
+				name:   "check-order",
+				action: o.checkModifiable,
+			},
+			{
+				name:   "validate-address",
+				action: o.validateModifiedAddress,
+			},
+			{
+				name:       "update-order",
+				action:     o.updateOrder,
+				compensate: o.restoreOrder,
+			},
+			{
+				name:       "update-shipment",
+				action:     o.updateShipment,
+				compensate: o.restoreUpdatedShipments,
+			},
+			{
+				name:   "settle-payment",
+				action: o.settleDifference,
+			},
+		},
+	}
+}
+
+func (o *Orchestrator) checkModifiable(saga *Saga) error {
+	order, err := o.loadUndispatchedOrder(saga, ErrOrderNotModifiable)
+	if err != nil {
+		return err
+	}
+	saga.Original = order
+	return nil
+}
+
+func (o *Orchestrator) validateModifiedAddress(saga *Saga) error {
+	if saga.Modification.Address == nil {
+		return nil
+	}
+	address, err := o.validateAddress(saga, *saga.Modification.Address)
+	if err != nil {
+		return err
+	}
+	saga.Modification.Address = address
+	return nil
+}
+
+func (o *Orchestrator) updateOrder(saga *Saga) error {
+	modifyReq := models.ModifyOrderRequest{
+		Items:           saga.Modification.Items,
+		ShippingAddress: saga.Modification.Address,
+	}
+	order, err := o.orders.ModifyOrder(sagaContext(saga), saga.OrderID, modifyReq)
+	if err != nil {
+		return err
+	}
+	saga.Amount = order.TotalPrice
+	return nil
+}
+
+func (o *Orchestrator) restoreOrder(saga *Saga) error {
+	restoreReq := models.ModifyOrderRequest{
+		Items:           saga.Original.Items,
+		ShippingAddress: &saga.Original.ShippingAddress,
+	}
+	_, err := o.orders.ModifyOrder(sagaContext(saga), saga.OrderID, restoreReq)
+	return err
+}
+
+// updateShipment books the order's shipments again for the modified order.
+func (o *Orchestrator) updateShipment(saga *Saga) error {
+	if len(saga.Shipments) == 0 {
+		return nil
+	}
+	if err := o.cancelShipping(saga); err != nil {
+		return err
+	}
+
+	err := o.reshipModifiedOrder(saga)
+	if err != nil {
+		// A failed step is not compensated, so put the old shipments back
+		// here.
+		if rebookErr := o.restoreShipments(saga); rebookErr != nil {
+			saga.CompensationErrors = append(saga.CompensationErrors, "update-shipment: "+rebookErr.Error())
+		}
+	}
+	return err
+}
+
+func (o *Orchestrator) restoreUpdatedShipments(saga *Saga) error {
+	if len(saga.Shipments) == 0 {
+		return nil
+	}
+	return o.restoreShipments(saga)
+}
+
+// settleDifference charges or refunds the difference between the modified
+// total and the original one. An extra charge held for fraud review parks
+// the saga until the payment service reports the decision.
+func (o *Orchestrator) settleDifference(saga *Saga) error {
+	difference := saga.Amount - saga.Original.TotalPrice
+	switch {
+	case math.Abs(difference) < 0.005:
+		return nil
+	case difference < 0:
+		return o.refundPayment(saga, -difference)
+	}
+
+	paymentReq := models.ProcessPaymentRequest{
+		OrderID:         saga.OrderID,
+		UserID:          saga.Original.UserID,
+		Amount:          difference,
+		PaymentMethodID: saga.Modification.PaymentMethodID,
+		CardNumber:      saga.Modification.CardNumber,
+		ShippingAddress: saga.Modification.Address,
+		CallbackURL:     o.reviewCallbackURL(saga),
+	}
+	payment, err := o.payments.ProcessPayment(sagaContext(saga), paymentReq)
+	if err != nil {
+		return err
+	}
+	saga.PaymentID = payment.ID
+	saga.Modification.CardNumber = ""
+
+	if payment.Status == models.PaymentStatusPendingReview {
+		return awaitEvent(SagaStatusAwaitingPaymentReview)
+	}
+	return nil
+}
+
+// reshipModifiedOrder books the cancelled shipments again for the modified
+// order, each with its own carrier and service level, to the new address
+// and with its items recomputed from the new order lines.
+func (o *Orchestrator) reshipModifiedOrder(saga *Saga) error {
+	items := reshippedItems(saga.Shipments, saga.Modification.Items)
+	for i, shipment := range saga.Shipments {
+		if items[i] != nil && len(items[i]) == 0 {
+			continue
+		}
+
+		shippingReq := rebookRequest(shipment)
+		shippingReq.Items = items[i]
+		if saga.Modification.Address != nil {
+			shippingReq.Address = *saga.Modification.Address
+		}
+		shippingResp, err := o.shipping.StartShipping(sagaContext(saga), shippingReq)
+		if err != nil {
+			return err
+		}
+		saga.ShippingID = shippingResp.ID
+	}
+	return nil
+}
+
+// restoreShipments cancels whatever the order ships now and books its
+// original shipments again.
+func (o *Orchestrator) restoreShipments(saga *Saga) error {
+	if err := o.cancelShipping(saga); err != nil {
+		return err
+	}
+	return o.rebookShipments(saga)
+}
+
+// reshippedItems splits the order lines over the order's shipments. Each
+// shipment keeps the products it carried, up to their new quantities, and
+// whatever is left over, such as added products, goes in the first one. A
+// shipment whose Items was empty held the whole order and still does (nil).
+// A shipment left with nothing to carry gets an empty, non-nil list. With
+// no new lines, the shipments keep their items.
+func reshippedItems(shipments []models.ShippingResponse, lines []models.OrderItem) [][]models.ShipmentItem {
+	items := make([][]models.ShipmentItem, len(shipments))
+	if lines == nil {
+		for i, shipment := range shipments {
+			items[i] = shipment.Items
+		}
+		return items
+	}
+
+	remaining := make(map[string]int)
+	var products []string
+	for _, line := range lines {
+		if _, seen := remaining[line.ProductID]; !seen {
+			products = append(products, line.ProductID)
+		}
+		remaining[line.ProductID] += line.Quantity
+	}
+
+	for i, shipment := range shipments {
+		if len(shipment.Items) == 0 {
+			// The whole order, whatever the other shipments carry.
+			for product := range remaining {
+				remaining[product] = 0
+			}
+			continue
+		}
+		items[i] = []models.ShipmentItem{}
+		for _, item := range shipment.Items {
+			quantity := item.Quantity
+			if quantity > remaining[item.ProductID] {
+				quantity = remaining[item.ProductID]
+			}
+			if quantity == 0 {
+				continue
+			}
+			items[i] = append(items[i], models.ShipmentItem{ProductID: item.ProductID, Quantity: quantity})
+			remaining[item.ProductID] -= quantity
+		}
+	}
+
+	for _, product := range products {
+		if remaining[product] > 0 && items[0] != nil {
+			items[0] = append(items[0], models.ShipmentItem{ProductID: product, Quantity: remaining[product]})
+		}
+	}
+	return items
+}
@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"saga-order-system/internal/models"
+)
+
+func TestReshippedItems(t *testing.T) {
+	split := []models.ShippingResponse{
+		{Items: []models.ShipmentItem{{ProductID: "p1", Quantity: 2}}},
+		{Items: []models.ShipmentItem{{ProductID: "p2", Quantity: 1}, {ProductID: "p3", Quantity: 1}}},
+	}
+	whole := []models.ShippingResponse{{}}
+
+	tests := []struct {
+		name      string
+		shipments []models.ShippingResponse
+		lines     []models.OrderItem
+		want      [][]models.ShipmentItem
+	}{
+		{
+			name:      "lines unchanged",
+			shipments: split,
+			lines:     nil,
+			want:      [][]models.ShipmentItem{split[0].Items, split[1].Items},
+		},
+		{
+			name:      "whole order",
+			shipments: whole,
+			lines:     []models.OrderItem{{ProductID: "p1", Quantity: 5}},
+			want:      [][]models.ShipmentItem{nil},
+		},
+		{
+			name:      "quantity reduced",
+			shipments: split,
+			lines:     []models.OrderItem{{ProductID: "p1", Quantity: 1}, {ProductID: "p2", Quantity: 1}, {ProductID: "p3", Quantity: 1}},
+			want: [][]models.ShipmentItem{
+				{{ProductID: "p1", Quantity: 1}},
+				{{ProductID: "p2", Quantity: 1}, {ProductID: "p3", Quantity: 1}},
+			},
+		},
+		{
+			name:      "product removed",
+			shipments: split,
+			lines:     []models.OrderItem{{ProductID: "p1", Quantity: 2}, {ProductID: "p3", Quantity: 1}},
+			want: [][]models.ShipmentItem{
+				{{ProductID: "p1", Quantity: 2}},
+				{{ProductID: "p3", Quantity: 1}},
+			},
+		},
+		{
+			name:      "shipment emptied",
+			shipments: split,
+			lines:     []models.OrderItem{{ProductID: "p1", Quantity: 2}},
+			want: [][]models.ShipmentItem{
+				{{ProductID: "p1", Quantity: 2}},
+				{},
+			},
+		},
+		{
+			name:      "quantity increased and product added",
+			shipments: split,
+			lines:     []models.OrderItem{{ProductID: "p1", Quantity: 2}, {ProductID: "p2", Quantity: 3}, {ProductID: "p3", Quantity: 1}, {ProductID: "p4", Quantity: 1}},
+			want: [][]models.ShipmentItem{
+				{{ProductID: "p1", Quantity: 2}, {ProductID: "p2", Quantity: 2}, {ProductID: "p4", Quantity: 1}},
+				{{ProductID: "p2", Quantity: 1}, {ProductID: "p3", Quantity: 1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reshippedItems(tt.shipments, tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("reshippedItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModifyOrderPaymentReview(t *testing.T) {
+	tests := []struct {
+		name       string
+		reviewed   models.PaymentStatus
+		wantStatus SagaStatus
+		wantItems  int
+	}{
+		{
+			name:       "captured extra charge completes the modification",
+			reviewed:   models.PaymentStatusSuccess,
+			wantStatus: SagaStatusCompleted,
+			wantItems:  3,
+		},
+		{
+			name:       "rejected extra charge restores the order",
+			reviewed:   models.PaymentStatusRejected,
+			wantStatus: SagaStatusCompensated,
+			wantItems:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeServices()
+			o := newFakeOrchestrator(f)
+
+			created, err := o.CreateOrderSaga(context.Background(), testOrderRequest())
+			if err != nil {
+				t.Fatalf("create order: %v", err)
+			}
+
+			f.paymentStatus = models.PaymentStatusPendingReview
+			saga, err := o.ModifyOrderSaga(context.Background(), created.OrderID, ModifyOrderRequest{
+				Items:      []models.OrderItem{{ProductID: "p1", Quantity: 3, Price: 10}},
+				CardNumber: "4242424242424242",
+			})
+			if err != nil || saga.Status != SagaStatusAwaitingPaymentReview {
+				t.Fatalf("modify status %s, error %v", saga.Status, err)
+			}
+			if saga.Modification.CardNumber != "" {
+				t.Error("card number kept on the parked saga")
+			}
+
+			f.mu.Lock()
+			payment := f.payments[saga.PaymentID]
+			payment.Status = tt.reviewed
+			f.payments[saga.PaymentID] = payment
+			f.mu.Unlock()
+
+			// A rejected charge ends the saga with the rejection as its error.
+			o.ResumePaymentReview(context.Background(), saga.ID)
+			resumed, _ := o.GetSaga(saga.ID)
+			if resumed.Status != tt.wantStatus {
+				t.Errorf("status %s, want %s", resumed.Status, tt.wantStatus)
+			}
+			if got := f.orders[created.OrderID].Items[0].Quantity; got != tt.wantItems {
+				t.Errorf("order quantity %d, want %d", got, tt.wantItems)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/config"
+	"saga-order-system/internal/metrics"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/tracing"
+)
+
+type CreateOrderRequest struct {
+	UserID          string             `json:"user_id"`
+	Items           []models.OrderItem `json:"items"`
+	Address         models.Address     `json:"address"`
+	BillingAddress  *models.Address    `json:"billing_address"`
+	PaymentMethodID string             `json:"payment_method_id"`
+	CardNumber      string             `json:"card_number"`
+	// ShippingCarrier books the given carrier; otherwise ShippingSelection
+	// ("CHEAPEST" or "FASTEST") picks one from the available quotes.
+	ShippingCarrier   string                  `json:"shipping_carrier"`
+	ShippingSelection models.CarrierSelection `json:"shipping_selection" binding:"omitempty,oneof=CHEAPEST FASTEST"`
+}
+
+type Orchestrator struct {
+	orderServiceURL    string
+	paymentServiceURL  string
+	shippingServiceURL string
+	// callbackURL is the orchestrator's own base URL, handed to services
+	// that report back asynchronously.
+	callbackURL string
+	client      *http.Client
+	orders      orderService
+	payments    paymentService
+	shipping    shippingService
+	sagas       *sagaStore
+	// retryAttempts and retryBackoff bound how hard a retriable step is
+	// retried before the saga stalls.
+	retryAttempts int
+	retryBackoff  time.Duration
+	definitions   []sagaDefinition
+	metrics       *sagaMetrics
+	tracer        *tracing.Tracer
+
+	// conns are the gRPC connections to the services, when they are called
+	// over gRPC.
+	conns []*grpc.ClientConn
+
+	// drainMu guards draining and inflight, the number of sagas running.
+	drainMu     sync.Mutex
+	draining    bool
+	inflight    int
+	interrupted atomic.Bool
+}
+
+// NewOrchestrator creates an orchestrator that records its saga and
+// downstream request metrics in registry and traces sagas with tracer. It
+// calls the services over the transport set in cfg and keeps its sagas in
+// cfg.SagaStoreFile, if set.
+func NewOrchestrator(cfg config.Config, registry *metrics.Registry, tracer *tracing.Tracer) (*Orchestrator, error) {
+	sagas, err := newSagaStore(cfg.SagaStoreFile)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Orchestrator{
+		orderServiceURL:    cfg.Order.URL,
+		paymentServiceURL:  cfg.Payment.URL,
+		shippingServiceURL: cfg.Shipping.URL,
+		callbackURL:        cfg.Orchestrator.URL,
+		client: &http.Client{
+			Timeout: cfg.HTTPTimeout.Std(),
+		},
+		sagas:         sagas,
+		retryAttempts: cfg.RetryAttempts,
+		retryBackoff:  cfg.RetryBackoff.Std(),
+		metrics:       newSagaMetrics(registry),
+		tracer:        tracer,
+	}
+	o.client.Transport = o.instrumentTransport(http.DefaultTransport)
+	if err := o.connectServices(cfg); err != nil {
+		return nil, err
+	}
+	o.definitions = o.registeredDefinitions()
+	return o, nil
+}
+
+func (o *Orchestrator) GetSaga(id string) (Saga, bool) {
+	return o.sagas.get(id)
+}
+
+func (o *Orchestrator) CreateOrderSaga(ctx context.Context, req CreateOrderRequest) (*Saga, error) {
+	saga := o.startSaga(SagaTypeCreateOrder)
+	saga.Request = &req
+	o.sagas.save(saga)
+
+	return o.start(ctx, saga)
+}
+
+// RetrySaga continues a stalled saga from the retriable step it stalled on.
+func (o *Orchestrator) RetrySaga(ctx context.Context, sagaID string) (*Saga, error) {
+	return o.resume(ctx, sagaID, SagaStatusStalled, true, nil)
+}
+
+// ResumePaymentReview continues a saga parked on a fraud review of its
+// payment when the payment was captured, and compensates it when it was
+// rejected. The outcome is read from the payment service rather than taken
+// from the caller, so only a finished review resumes the saga.
+func (o *Orchestrator) ResumePaymentReview(ctx context.Context, sagaID string) (*Saga, error) {
+	saga, err := o.parked(sagaID, SagaStatusAwaitingPaymentReview)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := o.payments.GetPayment(ctx, saga.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	switch payment.Status {
+	case models.PaymentStatusSuccess:
+		return o.resume(ctx, sagaID, SagaStatusAwaitingPaymentReview, true, nil)
+	case models.PaymentStatusRejected, models.PaymentStatusFailed, models.PaymentStatusVoided:
+		return o.resume(ctx, sagaID, SagaStatusAwaitingPaymentReview, false, errors.New("payment rejected in fraud review"))
+	default:
+		return nil, fmt.Errorf("%w: payment %s is still %s", ErrSagaNotResumable, payment.ID, payment.Status)
+	}
+}
+
+// createOrderDefinition is version 2 of the create-order saga, in which
+// taking the payment is the pivot and shipping is retried rather than undone.
+func (o *Orchestrator) createOrderDefinition() sagaDefinition {
+	return sagaDefinition{
+		sagaType:   SagaTypeCreateOrder,
+		version:    2,
+		locksOrder: true,
+		steps: []sagaStep{
+			{
+				name:   "validate-address",
+				action: o.validateOrderAddress,
+			},
+			{
+				name:       "create-order",
+				action:     o.placeOrder,
+				compensate: o.cancelOrder,
+			},
+			// Stock is reserved while the payment is taken; if either fails
+			// the other is undone. Once both have succeeded the order is paid
+			// for and only moves forward.
+			pivotGroup("reserve-and-charge",
+				sagaStep{
+					name:       "reserve-inventory",
+					action:     o.reserveStock,
+					compensate: o.releaseStock,
+				},
+				sagaStep{
+					name:       "process-payment",
+					action:     o.chargeOrder,
+					compensate: o.releasePayment,
+				},
+			),
+			{
+				name:   "start-shipping",
+				kind:   retriable,
+				action: o.shipOrder,
+			},
+		},
+	}
+}
+
+// createOrderDefinitionV1 is the create-order saga without a pivot, which
+// refunded the payment when shipping could not be started. Sagas started on
+// it still finish on it.
+func (o *Orchestrator) createOrderDefinitionV1() sagaDefinition {
+	return sagaDefinition{
+		sagaType:   SagaTypeCreateOrder,
+		version:    1,
+		locksOrder: true,
+		steps: []sagaStep{
+			{
+				name:   "validate-address",
+				action: o.validateOrderAddress,
+			},
+			{
+				name:       "create-order",
+				action:     o.placeOrder,
+				compensate: o.cancelOrder,
+			},
+			parallel("reserve-and-charge",
+				sagaStep{
+					name:       "reserve-inventory",
+					action:     o.reserveStock,
+					compensate: o.releaseStock,
+				},
+				sagaStep{
+					name:       "process-payment",
+					action:     o.chargeOrder,
+					compensate: o.releasePayment,
+				},
+			),
+			{
+				name:       "start-shipping",
+				action:     o.shipOrder,
+				compensate: o.cancelShipping,
+			},
+		},
+	}
+}
+
+func (o *Orchestrator) validateOrderAddress(saga *Saga) error {
+	address, err := o.validateAddress(saga, saga.Request.Address)
+	if err != nil {
+		return err
+	}
+	saga.Request.Address = *address
+	return nil
+}
+
+func (o *Orchestrator) placeOrder(saga *Saga) error {
+	orderResp, err := o.createOrder(saga)
+	if err != nil {
+		return err
+	}
+	// The order service creates the order locked by this saga.
+	saga.OrderID = orderResp.ID
+	saga.OrderLocked = true
+	saga.Amount = orderResp.TotalPrice
+	return nil
+}
+
+func (o *Orchestrator) reserveStock(saga *Saga) error {
+	reserveReq := models.ReserveStockRequest{
+		OrderID: saga.OrderID,
+		Items:   reservedItems(saga.Request.Items),
+	}
+	_, err := o.orders.ReserveStock(sagaContext(saga), reserveReq)
+	return err
+}
+
+func (o *Orchestrator) releaseStock(saga *Saga) error {
+	return o.orders.ReleaseStock(sagaContext(saga), saga.OrderID)
+}
+
+// chargeOrder takes the payment for the order, parking the saga when the
+// payment is held for fraud review until the payment service reports the
+// decision.
+func (o *Orchestrator) chargeOrder(saga *Saga) error {
+	paymentResp, err := o.processPayment(saga)
+	if err != nil {
+		return err
+	}
+	saga.PaymentID = paymentResp.ID
+	// The raw card number is not needed past this point.
+	saga.Request.CardNumber = ""
+
+	if paymentResp.Status == models.PaymentStatusPendingReview {
+		return awaitEvent(SagaStatusAwaitingPaymentReview)
+	}
+	return nil
+}
+
+// shipOrder books the order's shipment. A retry after a lost response finds
+// the shipment already booked.
+func (o *Orchestrator) shipOrder(saga *Saga) error {
+	shipment, err := o.activeShipment(saga)
+	if err != nil {
+		return err
+	}
+	if shipment == nil {
+		if shipment, err = o.startShipping(saga); err != nil {
+			return err
+		}
+	}
+	saga.ShippingID = shipment.ID
+	return nil
+}
+
+// validateAddress has the shipping service normalize the delivery address
+// so every later step works with the same canonical form.
+func (o *Orchestrator) validateAddress(saga *Saga, address models.Address) (*models.Address, error) {
+	normalized, err := o.shipping.ValidateAddress(sagaContext(saga), address)
+	if err != nil {
+		return nil, fmt.Errorf("address validation: %w", err)
+	}
+	return normalized, nil
+}
+
+func (o *Orchestrator) createOrder(saga *Saga) (*models.OrderResponse, error) {
+	orderReq := models.CreateOrderRequest{
+		UserID:          saga.Request.UserID,
+		Items:           saga.Request.Items,
+		ShippingAddress: saga.Request.Address,
+	}
+	return o.orders.CreateOrder(sagaContext(saga), orderReq)
+}
+
+// reservedItems lists the quantities of an order's lines to hold in stock.
+func reservedItems(items []models.OrderItem) []models.ShipmentItem {
+	reserved := make([]models.ShipmentItem, 0, len(items))
+	for _, item := range items {
+		reserved = append(reserved, models.ShipmentItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	return reserved
+}
+
+func (o *Orchestrator) processPayment(saga *Saga) (*models.PaymentResponse, error) {
+	req := saga.Request
+	paymentReq := models.ProcessPaymentRequest{
+		OrderID:         saga.OrderID,
+		UserID:          req.UserID,
+		Amount:          saga.Amount,
+		PaymentMethodID: req.PaymentMethodID,
+		CardNumber:      req.CardNumber,
+		BillingAddress:  req.BillingAddress,
+		ShippingAddress: &req.Address,
+		CallbackURL:     o.reviewCallbackURL(saga),
+	}
+	return o.payments.ProcessPayment(sagaContext(saga), paymentReq)
+}
+
+// reviewCallbackURL is where the payment service reports the outcome of the
+// fraud review of a payment the saga made.
+func (o *Orchestrator) reviewCallbackURL(saga *Saga) string {
+	return o.callbackURL + "/sagas/" + saga.ID + "/payment-review"
+}
+
+func (o *Orchestrator) startShipping(saga *Saga) (*models.ShippingResponse, error) {
+	req := saga.Request
+	shippingReq := models.StartShippingRequest{
+		OrderID:   saga.OrderID,
+		Address:   req.Address,
+		Carrier:   req.ShippingCarrier,
+		Selection: req.ShippingSelection,
+	}
+	return o.shipping.StartShipping(sagaContext(saga), shippingReq)
+}
+
+// activeShipment returns the order's outbound shipment that has not been
+// cancelled, or nil if there is none.
+func (o *Orchestrator) activeShipment(saga *Saga) (*models.ShippingResponse, error) {
+	shipments, err := o.shipping.ListOrderShippings(sagaContext(saga), saga.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	for i, shipment := range shipments {
+		if shipment.Kind == models.ShipmentKindOutbound && shipment.Status != models.ShippingStatusCancelled {
+			return &shipments[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (o *Orchestrator) cancelOrder(saga *Saga) error {
+	return o.orders.CancelOrder(sagaContext(saga), saga.OrderID)
+}
+
+// errNoPayment is returned by refundPayment when the order has no captured
+// payment, e.g. because it was voided after a rejected fraud review.
+var errNoPayment = errors.New("no captured payment for order")
+
+// refundPayment refunds amount of the order's payment, or all of it when
+// amount is zero.
+func (o *Orchestrator) refundPayment(saga *Saga, amount float64) error {
+	refundReq := models.RefundPaymentRequest{
+		OrderID: saga.OrderID,
+		Amount:  amount,
+	}
+
+	err := o.payments.RefundPayment(sagaContext(saga), refundReq)
+	if apierror.HasStatus(err, http.StatusNotFound) {
+		return errNoPayment
+	}
+	return err
+}
+
+// releasePayment gives the order's money back, whether the payment was
+// captured or is still held for fraud review. A held payment is voided so
+// that a later approval cannot capture it.
+func (o *Orchestrator) releasePayment(saga *Saga) error {
+	voidReq := models.VoidPaymentRequest{OrderID: saga.OrderID}
+	if err := o.payments.VoidPayment(sagaContext(saga), voidReq); err != nil {
+		return err
+	}
+	return o.refundOrder(saga)
+}
+
+func (o *Orchestrator) cancelShipping(saga *Saga) error {
+	cancelReq := models.CancelShippingRequest{OrderID: saga.OrderID}
+
+	err := o.shipping.CancelShipping(sagaContext(saga), cancelReq)
+	if apierror.HasStatus(err, http.StatusConflict) {
+		return fmt.Errorf("%w: %w: %w", ErrOrderNotCancellable, ErrOrderAlreadyShipped, err)
+	}
+	return err
+}
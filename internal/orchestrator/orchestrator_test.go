@@ -0,0 +1,134 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+func testOrderRequest() CreateOrderRequest {
+	return CreateOrderRequest{
+		UserID:     "u1",
+		Items:      []models.OrderItem{{ProductID: "p1", Quantity: 1, Price: 10}},
+		Address:    models.Address{Recipient: "A", Lines: []string{"1 Main St"}, City: "Springfield", Country: "US"},
+		CardNumber: "4242424242424242",
+	}
+}
+
+func TestCreateOrderCompensation(t *testing.T) {
+	outOfStock := apierror.New(http.StatusConflict, apierror.CodeInsufficientStock, "insufficient stock")
+	declined := apierror.New(http.StatusPaymentRequired, apierror.CodePaymentDeclined, "card declined")
+
+	tests := []struct {
+		name          string
+		paymentStatus models.PaymentStatus
+		fail          map[string]error
+		wantPayments  []models.PaymentStatus
+	}{
+		{
+			name:          "captured payment refunded when stock runs out",
+			paymentStatus: models.PaymentStatusSuccess,
+			fail:          map[string]error{"ReserveStock": outOfStock},
+			wantPayments:  []models.PaymentStatus{models.PaymentStatusRefunded},
+		},
+		{
+			name:          "payment held for review voided when stock runs out",
+			paymentStatus: models.PaymentStatusPendingReview,
+			fail:          map[string]error{"ReserveStock": outOfStock},
+			wantPayments:  []models.PaymentStatus{models.PaymentStatusVoided},
+		},
+		{
+			name: "declined payment releases the stock",
+			fail: map[string]error{"ProcessPayment": declined},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeServices()
+			f.paymentStatus = tt.paymentStatus
+			f.fail = tt.fail
+			o := newFakeOrchestrator(f)
+
+			saga, err := o.CreateOrderSaga(context.Background(), testOrderRequest())
+			if err == nil || saga.Status != SagaStatusCompensated || saga.FailedStep != "reserve-and-charge" {
+				t.Fatalf("status %s, failed step %q, error %v", saga.Status, saga.FailedStep, err)
+			}
+			if got := f.paymentsOf(saga.OrderID); !reflect.DeepEqual(got, tt.wantPayments) {
+				t.Errorf("payments %v, want %v", got, tt.wantPayments)
+			}
+			if f.reserved[saga.OrderID] {
+				t.Error("stock still reserved")
+			}
+			if status := f.orders[saga.OrderID].Status; status != models.OrderStatusCancelled {
+				t.Errorf("order %s, want %s", status, models.OrderStatusCancelled)
+			}
+		})
+	}
+}
+
+func TestResumePaymentReview(t *testing.T) {
+	tests := []struct {
+		name       string
+		reviewed   models.PaymentStatus
+		wantErr    error
+		wantStatus SagaStatus
+		wantShip   int
+	}{
+		{
+			name:       "captured payment ships the order",
+			reviewed:   models.PaymentStatusSuccess,
+			wantStatus: SagaStatusCompleted,
+			wantShip:   1,
+		},
+		{
+			name:       "rejected payment compensates",
+			reviewed:   models.PaymentStatusRejected,
+			wantStatus: SagaStatusCompensated,
+		},
+		{
+			name:       "payment still under review keeps the saga parked",
+			reviewed:   models.PaymentStatusPendingReview,
+			wantErr:    ErrSagaNotResumable,
+			wantStatus: SagaStatusAwaitingPaymentReview,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeServices()
+			f.paymentStatus = models.PaymentStatusPendingReview
+			o := newFakeOrchestrator(f)
+
+			saga, err := o.CreateOrderSaga(context.Background(), testOrderRequest())
+			if err != nil || saga.Status != SagaStatusAwaitingPaymentReview {
+				t.Fatalf("status %s, error %v", saga.Status, err)
+			}
+
+			// The review outcome only exists on the payment; the resume
+			// call carries none.
+			f.mu.Lock()
+			payment := f.payments[saga.PaymentID]
+			payment.Status = tt.reviewed
+			f.payments[saga.PaymentID] = payment
+			f.mu.Unlock()
+
+			_, err = o.ResumePaymentReview(context.Background(), saga.ID)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+			resumed, _ := o.GetSaga(saga.ID)
+			if resumed.Status != tt.wantStatus {
+				t.Errorf("status %s, want %s", resumed.Status, tt.wantStatus)
+			}
+			if got := f.count("StartShipping"); got != tt.wantShip {
+				t.Errorf("StartShipping called %d times, want %d", got, tt.wantShip)
+			}
+		})
+	}
+}
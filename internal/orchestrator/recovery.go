@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var errInterrupted = errors.New("saga interrupted by a restart")
+
+// Recover continues the sagas that were running or stalled when the
+// orchestrator last stopped, each on the definition version it started on.
+// Sagas awaiting an event stay parked until it arrives. It is meant to be
+// called once at startup, and stops once the orchestrator is draining.
+func (o *Orchestrator) Recover(ctx context.Context) {
+	for id, status := range o.sagas.withStatus(SagaStatusRunning, SagaStatusStalled) {
+		if _, err := o.recoverSaga(ctx, id, status); errors.Is(err, ErrShuttingDown) {
+			return
+		}
+	}
+}
+
+// recoverSaga continues a saga cut off by a restart. A stalled saga, or one
+// interrupted in a retriable step, runs on from where it was. Any other step
+// may or may not have taken effect and cannot safely be repeated, so the
+// saga is compensated instead. The branches of an interrupted parallel group
+// are compensated too, as their compensations tolerate branches that never
+// ran.
+func (o *Orchestrator) recoverSaga(ctx context.Context, id string, status SagaStatus) (_ *Saga, err error) {
+	if !o.enter(true) {
+		return nil, ErrShuttingDown
+	}
+	defer o.leave()
+
+	claimed, err := o.sagas.claim(id, status)
+	if err != nil {
+		return nil, err
+	}
+	saga := &claimed
+
+	_, span := o.tracer.Start(ctx, "saga "+string(saga.Type)+" recover")
+	o.beginRun(ctx, saga, span)
+	defer func() { endRun(saga, err) }()
+	saga.logger.Info("Saga recovered", "status", status, "definition_version", saga.DefinitionVersion)
+
+	def, err := o.definition(saga)
+	if err != nil {
+		return o.endSaga(saga, SagaStatusFailed, err)
+	}
+
+	if status == SagaStatusRunning && saga.CurrentStep < len(def.steps) {
+		step := def.steps[saga.CurrentStep]
+		if step.kind != retriable {
+			saga.FailedStep = step.name
+			if err := compensateBranches(saga, step.branches); err != nil {
+				o.metrics.compensationFailures.Inc(string(saga.Type), step.name)
+				saga.logger.Warn("Failed to compensate step", "step", step.name, "error", err)
+				saga.CompensationErrors = append(saga.CompensationErrors, fmt.Sprintf("%s: %v", step.name, err))
+			}
+			return o.compensate(saga, fmt.Errorf("%w during %s", errInterrupted, step.name))
+		}
+	}
+	return o.run(saga)
+}
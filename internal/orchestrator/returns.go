@@ -0,0 +1,213 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"saga-order-system/internal/models"
+)
+
+// ReturnSaga runs the create saga in reverse for delivered goods: it opens
+// a return and books the return shipment, then waits for the goods to reach
+// the warehouse before restocking and refunding.
+func (o *Orchestrator) ReturnSaga(ctx context.Context, req models.CreateReturnRequest) (*Saga, error) {
+	saga := o.startSaga(SagaTypeReturn)
+	saga.OrderID = req.OrderID
+	saga.ReturnRequest = &req
+	o.sagas.save(saga)
+
+	return o.start(ctx, saga)
+}
+
+// ResumeReturnReceipt continues a return saga once its return shipment has
+// been delivered to the warehouse, which it checks with the shipping
+// service.
+func (o *Orchestrator) ResumeReturnReceipt(ctx context.Context, sagaID string) (*Saga, error) {
+	saga, err := o.parked(sagaID, SagaStatusAwaitingReturnReceipt)
+	if err != nil {
+		return nil, err
+	}
+
+	shipments, err := o.shipping.ListOrderShippings(ctx, saga.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, shipment := range shipments {
+		if shipment.ID != saga.ShippingID {
+			continue
+		}
+		if shipment.Status != models.ShippingStatusDelivered {
+			return nil, fmt.Errorf("%w: return shipment %s is %s", ErrSagaNotResumable, shipment.ID, shipment.Status)
+		}
+		return o.resume(ctx, sagaID, SagaStatusAwaitingReturnReceipt, true, nil)
+	}
+	return nil, fmt.Errorf("%w: return shipment %s not found", ErrSagaNotResumable, saga.ShippingID)
+}
+
+// returnDefinition is version 2 of the return saga, in which receiving the
+// goods is the pivot and the steps after it are retried rather than undone.
+func (o *Orchestrator) returnDefinition() sagaDefinition {
+	return sagaDefinition{
+		sagaType: SagaTypeReturn,
+		version:  2,
+		steps: []sagaStep{
+			{
+				name:       "open-return",
+				action:     o.openReturn,
+				compensate: o.cancelReturn,
+			},
+			{
+				name:       "create-return-shipment",
+				action:     o.bookReturnShipment,
+				compensate: o.cancelReturnShipment,
+			},
+			// The goods are back in the warehouse; from here the return is
+			// only driven forward.
+			{
+				name:   "receive-goods",
+				kind:   pivot,
+				action: o.receiveGoods,
+			},
+			{
+				name:   "restock-inventory",
+				kind:   retriable,
+				action: o.restockReturn,
+			},
+			{
+				name:   "refund-payment",
+				kind:   retriable,
+				action: o.refundReturn,
+			},
+			{
+				name:   "complete-return",
+				kind:   retriable,
+				action: o.completeReturn,
+			},
+		},
+	}
+}
+
+// returnDefinitionV1 is the return saga in which every step was undone when
+// a later one failed, taking the restocked units off hand again. Sagas
+// started on it still finish on it.
+func (o *Orchestrator) returnDefinitionV1() sagaDefinition {
+	return sagaDefinition{
+		sagaType: SagaTypeReturn,
+		version:  1,
+		steps: []sagaStep{
+			{
+				name:       "open-return",
+				action:     o.openReturn,
+				compensate: o.cancelReturn,
+			},
+			{
+				name:       "create-return-shipment",
+				action:     o.bookReturnShipment,
+				compensate: o.cancelReturnShipment,
+			},
+			{
+				name:   "receive-goods",
+				action: o.receiveGoods,
+			},
+			{
+				name:       "restock-inventory",
+				action:     o.restockReturn,
+				compensate: o.deductReturn,
+			},
+			{
+				name:   "refund-payment",
+				action: o.refundReturn,
+			},
+			{
+				name:   "complete-return",
+				action: o.completeReturn,
+			},
+		},
+	}
+}
+
+func (o *Orchestrator) openReturn(saga *Saga) error {
+	ret, err := o.orders.CreateReturn(sagaContext(saga), *saga.ReturnRequest)
+	if err != nil {
+		return err
+	}
+	saga.Return = ret
+	saga.Amount = ret.RefundAmount
+	return nil
+}
+
+func (o *Orchestrator) cancelReturn(saga *Saga) error {
+	return o.orders.CancelReturn(sagaContext(saga), saga.Return.ID)
+}
+
+// bookReturnShipment books the pickup of the returned goods and parks the
+// saga until the carrier has delivered them back.
+func (o *Orchestrator) bookReturnShipment(saga *Saga) error {
+	shippingReq := models.StartShippingRequest{
+		OrderID:     saga.OrderID,
+		Kind:        models.ShipmentKindReturn,
+		Items:       saga.Return.Items,
+		Address:     saga.Return.PickupAddress,
+		CallbackURL: o.callbackURL + "/sagas/" + saga.ID + "/return-received",
+	}
+	shippingResp, err := o.shipping.StartShipping(sagaContext(saga), shippingReq)
+	if err != nil {
+		return err
+	}
+	saga.ShippingID = shippingResp.ID
+
+	return awaitEvent(SagaStatusAwaitingReturnReceipt)
+}
+
+func (o *Orchestrator) cancelReturnShipment(saga *Saga) error {
+	cancelReq := models.CancelShippingRequest{
+		OrderID:    saga.OrderID,
+		ShippingID: saga.ShippingID,
+	}
+	return o.shipping.CancelShipping(sagaContext(saga), cancelReq)
+}
+
+func (o *Orchestrator) receiveGoods(saga *Saga) error {
+	ret, err := o.orders.ReceiveReturn(sagaContext(saga), saga.Return.ID)
+	if err != nil {
+		return err
+	}
+	saga.Return = ret
+	return nil
+}
+
+// restockReturn and refundReturn carry the saga's ID, so that a retry after
+// a lost response is not applied twice.
+func (o *Orchestrator) restockReturn(saga *Saga) error {
+	stockReq := models.StockAdjustmentRequest{
+		Items:     saga.Return.Items,
+		Returned:  true,
+		Reference: saga.ID,
+	}
+	_, err := o.orders.Restock(sagaContext(saga), stockReq)
+	return err
+}
+
+func (o *Orchestrator) deductReturn(saga *Saga) error {
+	stockReq := models.StockAdjustmentRequest{Items: saga.Return.Items, Returned: true}
+	_, err := o.orders.DeductStock(sagaContext(saga), stockReq)
+	return err
+}
+
+func (o *Orchestrator) refundReturn(saga *Saga) error {
+	refundReq := models.RefundPaymentRequest{
+		OrderID:   saga.OrderID,
+		Amount:    saga.Amount,
+		Reference: saga.ID,
+	}
+	return o.payments.RefundPayment(sagaContext(saga), refundReq)
+}
+
+func (o *Orchestrator) completeReturn(saga *Saga) error {
+	ret, err := o.orders.CompleteReturn(sagaContext(saga), saga.Return.ID)
+	if err != nil {
+		return err
+	}
+	saga.Return = ret
+	return nil
+}
@@ -0,0 +1,198 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+// startReturn runs a create-order saga and opens a return of its items,
+// leaving the return saga waiting for the goods.
+func startReturn(t *testing.T, f *fakeServices, o *Orchestrator) *Saga {
+	t.Helper()
+	order, err := o.CreateOrderSaga(context.Background(), testOrderRequest())
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	saga, err := o.ReturnSaga(context.Background(), models.CreateReturnRequest{
+		OrderID: order.OrderID,
+		Items:   []models.ShipmentItem{{ProductID: "p1", Quantity: 1}},
+	})
+	if err != nil || saga.Status != SagaStatusAwaitingReturnReceipt {
+		t.Fatalf("return status %s, error %v", saga.Status, err)
+	}
+	return saga
+}
+
+// setShipmentStatus moves the shipment as the carrier would.
+func (f *fakeServices) setShipmentStatus(shippingID string, status models.ShippingStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	shipment := f.shipments[shippingID]
+	shipment.Status = status
+	f.shipments[shippingID] = shipment
+}
+
+func TestResumeReturnReceipt(t *testing.T) {
+	tests := []struct {
+		name       string
+		shipment   models.ShippingStatus
+		wantErr    error
+		wantStatus SagaStatus
+	}{
+		{
+			name:       "delivered goods complete the return",
+			shipment:   models.ShippingStatusDelivered,
+			wantStatus: SagaStatusCompleted,
+		},
+		{
+			name:       "goods still in transit keep the saga parked",
+			shipment:   models.ShippingStatusInTransit,
+			wantErr:    ErrSagaNotResumable,
+			wantStatus: SagaStatusAwaitingReturnReceipt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeServices()
+			o := newFakeOrchestrator(f)
+			saga := startReturn(t, f, o)
+			f.setShipmentStatus(saga.ShippingID, tt.shipment)
+
+			_, err := o.ResumeReturnReceipt(context.Background(), saga.ID)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+			resumed, _ := o.GetSaga(saga.ID)
+			if resumed.Status != tt.wantStatus {
+				t.Errorf("status %s, want %s", resumed.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestReturnDefinitionVersions(t *testing.T) {
+	refundFailed := apierror.New(http.StatusBadGateway, apierror.CodeUpstreamFailed, "gateway unavailable")
+
+	tests := []struct {
+		name       string
+		version    int
+		wantStatus SagaStatus
+		wantStock  int
+		wantReturn models.ReturnStatus
+	}{
+		{
+			name:       "v1 takes the restocked units off hand again",
+			version:    1,
+			wantStatus: SagaStatusCompensated,
+			wantStock:  0,
+			wantReturn: models.ReturnStatusCancelled,
+		},
+		{
+			name:       "v2 stalls past the pivot and keeps the units",
+			version:    2,
+			wantStatus: SagaStatusStalled,
+			wantStock:  1,
+			wantReturn: models.ReturnStatusReceived,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeServices()
+			o := newFakeOrchestrator(f)
+			order, err := o.CreateOrderSaga(context.Background(), testOrderRequest())
+			if err != nil {
+				t.Fatalf("create order: %v", err)
+			}
+
+			saga := o.startSaga(SagaTypeReturn)
+			saga.DefinitionVersion = tt.version
+			saga.OrderID = order.OrderID
+			saga.ReturnRequest = &models.CreateReturnRequest{
+				OrderID: order.OrderID,
+				Items:   []models.ShipmentItem{{ProductID: "p1", Quantity: 1}},
+			}
+			o.sagas.save(saga)
+			if _, err := o.start(context.Background(), saga); err != nil {
+				t.Fatalf("start: %v", err)
+			}
+
+			f.setShipmentStatus(saga.ShippingID, models.ShippingStatusDelivered)
+			f.fail["RefundPayment"] = refundFailed
+			resumed, err := o.ResumeReturnReceipt(context.Background(), saga.ID)
+			if err == nil || resumed.Status != tt.wantStatus || resumed.FailedStep != "refund-payment" {
+				t.Fatalf("status %s, failed step %q, error %v", resumed.Status, resumed.FailedStep, err)
+			}
+			if got := f.stock["p1"]; got != tt.wantStock {
+				t.Errorf("stock %d, want %d", got, tt.wantStock)
+			}
+			if got := f.returns[saga.Return.ID].Status; got != tt.wantReturn {
+				t.Errorf("return %s, want %s", got, tt.wantReturn)
+			}
+		})
+	}
+}
+
+func TestReturnSaga(t *testing.T) {
+	unavailable := apierror.Conflict("carrier unavailable")
+
+	t.Run("failed pickup booking cancels the return", func(t *testing.T) {
+		f := newFakeServices()
+		o := newFakeOrchestrator(f)
+		order, err := o.CreateOrderSaga(context.Background(), testOrderRequest())
+		if err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+
+		f.fail["StartShipping"] = unavailable
+		saga, err := o.ReturnSaga(context.Background(), models.CreateReturnRequest{
+			OrderID: order.OrderID,
+			Items:   []models.ShipmentItem{{ProductID: "p1", Quantity: 1}},
+		})
+		if err == nil || saga.Status != SagaStatusCompensated || saga.FailedStep != "create-return-shipment" {
+			t.Fatalf("status %s, failed step %q, error %v", saga.Status, saga.FailedStep, err)
+		}
+		if got := f.returns[saga.Return.ID].Status; got != models.ReturnStatusCancelled {
+			t.Errorf("return %s, want %s", got, models.ReturnStatusCancelled)
+		}
+		if n := f.count("RefundPayment"); n != 0 {
+			t.Errorf("RefundPayment called %d times", n)
+		}
+	})
+
+	t.Run("stalled refund completes on retry", func(t *testing.T) {
+		f := newFakeServices()
+		o := newFakeOrchestrator(f)
+		saga := startReturn(t, f, o)
+		f.setShipmentStatus(saga.ShippingID, models.ShippingStatusDelivered)
+
+		f.fail["RefundPayment"] = unavailable
+		stalled, err := o.ResumeReturnReceipt(context.Background(), saga.ID)
+		if err == nil || stalled.Status != SagaStatusStalled {
+			t.Fatalf("status %s, error %v", stalled.Status, err)
+		}
+
+		delete(f.fail, "RefundPayment")
+		retried, err := o.RetrySaga(context.Background(), saga.ID)
+		if err != nil || retried.Status != SagaStatusCompleted {
+			t.Fatalf("retry status %s, error %v", retried.Status, err)
+		}
+		if got := f.paymentsOf(saga.OrderID); len(got) != 1 || got[0] != models.PaymentStatusRefunded {
+			t.Errorf("payments %v, want one refunded", got)
+		}
+		if got := f.returns[saga.Return.ID].Status; got != models.ReturnStatusCompleted {
+			t.Errorf("return %s, want %s", got, models.ReturnStatusCompleted)
+		}
+		// The retry starts at the stalled step, so the goods are restocked
+		// once.
+		if n := f.count("Restock"); n != 1 {
+			t.Errorf("Restock called %d times, want 1", n)
+		}
+	})
+}
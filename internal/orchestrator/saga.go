@@ -0,0 +1,425 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/tracing"
+)
+
+var (
+	ErrSagaNotFound     = errors.New("saga not found")
+	ErrSagaNotResumable = errors.New("saga cannot be resumed")
+	// ErrDefinitionNotFound means the definition version a saga started on
+	// is no longer registered.
+	ErrDefinitionNotFound = errors.New("saga definition not found")
+)
+
+type SagaType string
+
+const (
+	SagaTypeCreateOrder SagaType = "CREATE_ORDER"
+	SagaTypeReturn      SagaType = "RETURN"
+	SagaTypeCancelOrder SagaType = "CANCEL_ORDER"
+	SagaTypeModifyOrder SagaType = "MODIFY_ORDER"
+)
+
+type SagaStatus string
+
+const (
+	SagaStatusRunning               SagaStatus = "RUNNING"
+	SagaStatusAwaitingPaymentReview SagaStatus = "AWAITING_PAYMENT_REVIEW"
+	SagaStatusAwaitingReturnReceipt SagaStatus = "AWAITING_RETURN_RECEIPT"
+	SagaStatusStalled               SagaStatus = "STALLED"
+	SagaStatusCompleted             SagaStatus = "COMPLETED"
+	SagaStatusCompensated           SagaStatus = "COMPENSATED"
+	SagaStatusFailed                SagaStatus = "FAILED"
+)
+
+// Saga records the progress of one saga instance so that sagas parked on an
+// external decision can be resumed later.
+type Saga struct {
+	ID                 string                      `json:"id"`
+	Type               SagaType                    `json:"type"`
+	DefinitionVersion  int                         `json:"definition_version"`
+	OrderID            string                      `json:"order_id,omitempty"`
+	OrderLocked        bool                        `json:"order_locked,omitempty"`
+	Amount             float64                     `json:"amount,omitempty"`
+	PaymentID          string                      `json:"payment_id,omitempty"`
+	ShippingID         string                      `json:"shipping_id,omitempty"`
+	Status             SagaStatus                  `json:"status"`
+	CurrentStep        int                         `json:"current_step"`
+	CompletedSteps     []string                    `json:"completed_steps"`
+	Error              string                      `json:"error,omitempty"`
+	FailedStep         string                      `json:"failed_step,omitempty"`
+	CompensationErrors []string                    `json:"compensation_errors,omitempty"`
+	Request            *CreateOrderRequest         `json:"request,omitempty"`
+	ReturnRequest      *models.CreateReturnRequest `json:"return_request,omitempty"`
+	Return             *models.Return              `json:"return,omitempty"`
+	Shipments          []models.ShippingResponse   `json:"shipments,omitempty"`
+	Modification       *ModifyOrderRequest         `json:"modification,omitempty"`
+	Original           *models.OrderResponse       `json:"original,omitempty"`
+	TraceID            string                      `json:"trace_id,omitempty"`
+	CreatedAt          time.Time                   `json:"created_at"`
+	UpdatedAt          time.Time                   `json:"updated_at"`
+
+	// runSpan covers the current run of the saga and span the step or
+	// compensation in progress, which requests made for the saga continue.
+	runSpan *tracing.Span
+	span    *tracing.Span
+	// logger and requestID come from the request that started or resumed
+	// the saga.
+	logger    *logging.Logger
+	requestID string
+}
+
+// SagaResponse is a saga as the API shows it. The order and modification
+// requests it carries are shown without their card numbers.
+type SagaResponse struct {
+	ID                 string                      `json:"id"`
+	Type               SagaType                    `json:"type"`
+	DefinitionVersion  int                         `json:"definition_version"`
+	OrderID            string                      `json:"order_id,omitempty"`
+	OrderLocked        bool                        `json:"order_locked,omitempty"`
+	Amount             float64                     `json:"amount,omitempty"`
+	PaymentID          string                      `json:"payment_id,omitempty"`
+	ShippingID         string                      `json:"shipping_id,omitempty"`
+	Status             SagaStatus                  `json:"status"`
+	CurrentStep        int                         `json:"current_step"`
+	CompletedSteps     []string                    `json:"completed_steps"`
+	Error              string                      `json:"error,omitempty"`
+	FailedStep         string                      `json:"failed_step,omitempty"`
+	CompensationErrors []string                    `json:"compensation_errors,omitempty"`
+	Request            *SagaOrderRequest           `json:"request,omitempty"`
+	ReturnRequest      *models.CreateReturnRequest `json:"return_request,omitempty"`
+	Return             *models.Return              `json:"return,omitempty"`
+	Shipments          []models.ShippingResponse   `json:"shipments,omitempty"`
+	Modification       *SagaModification           `json:"modification,omitempty"`
+	Original           *models.OrderResponse       `json:"original,omitempty"`
+	TraceID            string                      `json:"trace_id,omitempty"`
+	CreatedAt          time.Time                   `json:"created_at"`
+	UpdatedAt          time.Time                   `json:"updated_at"`
+}
+
+// SagaOrderRequest is the CreateOrderRequest a saga was started with.
+type SagaOrderRequest struct {
+	UserID            string                  `json:"user_id"`
+	Items             []models.OrderItem      `json:"items"`
+	Address           models.Address          `json:"address"`
+	BillingAddress    *models.Address         `json:"billing_address"`
+	PaymentMethodID   string                  `json:"payment_method_id"`
+	ShippingCarrier   string                  `json:"shipping_carrier"`
+	ShippingSelection models.CarrierSelection `json:"shipping_selection"`
+}
+
+// SagaModification is the ModifyOrderRequest a saga was started with.
+type SagaModification struct {
+	Items           []models.OrderItem `json:"items"`
+	Address         *models.Address    `json:"address"`
+	PaymentMethodID string             `json:"payment_method_id"`
+}
+
+// Response returns the saga as the API shows it.
+func (s Saga) Response() SagaResponse {
+	resp := SagaResponse{
+		ID:                 s.ID,
+		Type:               s.Type,
+		DefinitionVersion:  s.DefinitionVersion,
+		OrderID:            s.OrderID,
+		OrderLocked:        s.OrderLocked,
+		Amount:             s.Amount,
+		PaymentID:          s.PaymentID,
+		ShippingID:         s.ShippingID,
+		Status:             s.Status,
+		CurrentStep:        s.CurrentStep,
+		CompletedSteps:     s.CompletedSteps,
+		Error:              s.Error,
+		FailedStep:         s.FailedStep,
+		CompensationErrors: s.CompensationErrors,
+		ReturnRequest:      s.ReturnRequest,
+		Return:             s.Return,
+		Shipments:          s.Shipments,
+		Original:           s.Original,
+		TraceID:            s.TraceID,
+		CreatedAt:          s.CreatedAt,
+		UpdatedAt:          s.UpdatedAt,
+	}
+	if req := s.Request; req != nil {
+		resp.Request = &SagaOrderRequest{
+			UserID:            req.UserID,
+			Items:             req.Items,
+			Address:           req.Address,
+			BillingAddress:    req.BillingAddress,
+			PaymentMethodID:   req.PaymentMethodID,
+			ShippingCarrier:   req.ShippingCarrier,
+			ShippingSelection: req.ShippingSelection,
+		}
+	}
+	if mod := s.Modification; mod != nil {
+		resp.Modification = &SagaModification{
+			Items:           mod.Items,
+			Address:         mod.Address,
+			PaymentMethodID: mod.PaymentMethodID,
+		}
+	}
+	return resp
+}
+
+// sagaStore keeps every saga in memory and, when path is set, writes them
+// all to that JSON file on each change so that a restarted orchestrator can
+// pick up where it left off.
+type sagaStore struct {
+	sagas map[string]Saga
+	path  string
+	mu    sync.RWMutex
+}
+
+// newSagaStore creates a store persisted to path, loading the sagas already
+// saved there. An empty path keeps the sagas in memory only.
+func newSagaStore(path string) (*sagaStore, error) {
+	s := &sagaStore{
+		sagas: make(map[string]Saga),
+		path:  path,
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read saga store: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.sagas); err != nil {
+		return nil, fmt.Errorf("parse saga store %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func newSaga(sagaType SagaType) *Saga {
+	now := time.Now()
+	return &Saga{
+		ID:             uuid.New().String(),
+		Type:           sagaType,
+		Status:         SagaStatusRunning,
+		CompletedSteps: []string{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// save stores a copy of the saga, so that readers of the store do not see
+// the saga change under them while it runs on. get and claim likewise hand
+// out copies.
+func (s *sagaStore) save(saga *Saga) {
+	saga.UpdatedAt = time.Now()
+
+	s.mu.Lock()
+	s.sagas[saga.ID] = saga.clone()
+	s.persist()
+	s.mu.Unlock()
+}
+
+func (s *sagaStore) get(id string) (Saga, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	saga, exists := s.sagas[id]
+	return saga.clone(), exists
+}
+
+// claim moves a saga out of the expected status so that only one caller can
+// resume it.
+func (s *sagaStore) claim(id string, expected SagaStatus) (Saga, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saga, exists := s.sagas[id]
+	if !exists {
+		return Saga{}, ErrSagaNotFound
+	}
+	if saga.Status != expected {
+		return Saga{}, fmt.Errorf("%w: saga is %s", ErrSagaNotResumable, saga.Status)
+	}
+
+	saga.Status = SagaStatusRunning
+	saga.UpdatedAt = time.Now()
+	s.sagas[id] = saga
+	s.persist()
+	return saga.clone(), nil
+}
+
+// withStatus returns the IDs of the sagas in any of statuses.
+func (s *sagaStore) withStatus(statuses ...SagaStatus) map[string]SagaStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make(map[string]SagaStatus)
+	for id, saga := range s.sagas {
+		for _, status := range statuses {
+			if saga.Status == status {
+				ids[id] = status
+			}
+		}
+	}
+	return ids
+}
+
+// lockHolder returns the ID of an unfinished saga other than sagaID that
+// holds the order's lock. A saga parked or stalled keeps holding it even
+// once the order service's lock has expired.
+func (s *sagaStore) lockHolder(orderID, sagaID string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for id, saga := range s.sagas {
+		if id == sagaID || saga.OrderID != orderID || !saga.OrderLocked {
+			continue
+		}
+		switch saga.Status {
+		case SagaStatusCompleted, SagaStatusCompensated, SagaStatusFailed:
+			continue
+		}
+		return id, true
+	}
+	return "", false
+}
+
+// persist writes the sagas to s.path, replacing the file only once the new
+// one is complete. Card numbers are never written. Callers must hold s.mu.
+func (s *sagaStore) persist() {
+	if s.path == "" {
+		return
+	}
+
+	sagas := make(map[string]Saga, len(s.sagas))
+	for id, saga := range s.sagas {
+		if saga.Request != nil && saga.Request.CardNumber != "" {
+			req := *saga.Request
+			req.CardNumber = ""
+			saga.Request = &req
+		}
+		if saga.Modification != nil && saga.Modification.CardNumber != "" {
+			mod := *saga.Modification
+			mod.CardNumber = ""
+			saga.Modification = &mod
+		}
+		sagas[id] = saga
+	}
+
+	if err := writeFile(s.path, sagas); err != nil {
+		logging.Default().Error("Failed to persist sagas", "path", s.path, "error", err)
+	}
+}
+
+func writeFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// clone returns a copy of the saga that shares no memory with it.
+func (s *Saga) clone() Saga {
+	c := *s
+	c.CompletedSteps = cloneSlice(s.CompletedSteps)
+	c.CompensationErrors = cloneSlice(s.CompensationErrors)
+	c.Shipments = cloneShipments(s.Shipments)
+
+	if s.Request != nil {
+		req := *s.Request
+		req.Items = cloneSlice(req.Items)
+		req.Address = cloneAddress(req.Address)
+		req.BillingAddress = cloneAddressRef(req.BillingAddress)
+		c.Request = &req
+	}
+	if s.ReturnRequest != nil {
+		req := *s.ReturnRequest
+		req.Items = cloneSlice(req.Items)
+		c.ReturnRequest = &req
+	}
+	if s.Return != nil {
+		ret := *s.Return
+		ret.Items = cloneSlice(ret.Items)
+		ret.PickupAddress = cloneAddress(ret.PickupAddress)
+		c.Return = &ret
+	}
+	if s.Modification != nil {
+		mod := *s.Modification
+		mod.Items = cloneSlice(mod.Items)
+		mod.Address = cloneAddressRef(mod.Address)
+		c.Modification = &mod
+	}
+	if s.Original != nil {
+		order := *s.Original
+		order.Items = cloneSlice(order.Items)
+		order.ShippingAddress = cloneAddress(order.ShippingAddress)
+		order.Shipments = cloneSlice(order.Shipments)
+		for i := range order.Shipments {
+			order.Shipments[i].Items = cloneSlice(order.Shipments[i].Items)
+		}
+		if order.Lock != nil {
+			lock := *order.Lock
+			order.Lock = &lock
+		}
+		c.Original = &order
+	}
+	return c
+}
+
+func cloneShipments(shipments []models.ShippingResponse) []models.ShippingResponse {
+	c := cloneSlice(shipments)
+	for i := range c {
+		c[i].Items = cloneSlice(c[i].Items)
+		c[i].Address = cloneAddress(c[i].Address)
+		c[i].Events = cloneSlice(c[i].Events)
+	}
+	return c
+}
+
+func cloneAddress(address models.Address) models.Address {
+	address.Lines = cloneSlice(address.Lines)
+	return address
+}
+
+func cloneAddressRef(address *models.Address) *models.Address {
+	if address == nil {
+		return nil
+	}
+	c := cloneAddress(*address)
+	return &c
+}
+
+// cloneSlice copies s, keeping a nil slice nil and an empty one empty.
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"saga-order-system/internal/models"
+)
+
+func TestSagaStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sagas.json")
+
+	store, err := newSagaStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saga := newSaga(SagaTypeCreateOrder)
+	saga.DefinitionVersion = 1
+	saga.CurrentStep = 2
+	saga.Request = &CreateOrderRequest{UserID: "u1", CardNumber: "4242424242424242"}
+	saga.Modification = &ModifyOrderRequest{CardNumber: "4000000000000002"}
+	store.save(saga)
+
+	if _, err := store.claim(saga.ID, SagaStatusRunning); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+
+	reloaded, err := newSagaStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := reloaded.get(saga.ID)
+	if !ok {
+		t.Fatalf("saga %s not reloaded", saga.ID)
+	}
+	if got.DefinitionVersion != 1 || got.CurrentStep != 2 || got.Status != SagaStatusRunning || got.Request.UserID != "u1" {
+		t.Errorf("reloaded saga = %+v", got)
+	}
+	if got.Request.CardNumber != "" || got.Modification.CardNumber != "" {
+		t.Errorf("card numbers %q, %q persisted", got.Request.CardNumber, got.Modification.CardNumber)
+	}
+	// The saga in memory keeps the card until the payment is taken.
+	if current, _ := store.get(saga.ID); current.Request.CardNumber == "" {
+		t.Error("card number cleared in memory")
+	}
+
+	ids := reloaded.withStatus(SagaStatusRunning, SagaStatusStalled)
+	if len(ids) != 1 || ids[saga.ID] != SagaStatusRunning {
+		t.Errorf("withStatus = %v", ids)
+	}
+}
+
+func TestSagaStoreInMemory(t *testing.T) {
+	store, err := newSagaStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saga := newSaga(SagaTypeReturn)
+	store.save(saga)
+	if _, ok := store.get(saga.ID); !ok {
+		t.Fatal("saga not saved")
+	}
+}
+
+func TestSagaResponseOmitsCardNumbers(t *testing.T) {
+	saga := newSaga(SagaTypeModifyOrder)
+	saga.Request = &CreateOrderRequest{UserID: "u1", CardNumber: "4242424242424242"}
+	saga.Modification = &ModifyOrderRequest{PaymentMethodID: "pm1", CardNumber: "4000000000000002"}
+
+	body, err := json.Marshal(saga.Response())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, card := range []string{"4242424242424242", "4000000000000002", "card_number"} {
+		if strings.Contains(string(body), card) {
+			t.Errorf("response %s contains %q", body, card)
+		}
+	}
+	if !strings.Contains(string(body), `"payment_method_id":"pm1"`) {
+		t.Errorf("response %s lost the payment method", body)
+	}
+}
+
+func TestSagaStoreKeepsCopies(t *testing.T) {
+	store, err := newSagaStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saga := newSaga(SagaTypeModifyOrder)
+	saga.Request = &CreateOrderRequest{CardNumber: "4242424242424242", Address: models.Address{Lines: []string{"1 Main St"}}}
+	saga.Modification = &ModifyOrderRequest{Items: []models.OrderItem{{ProductID: "p1", Quantity: 1}}}
+	store.save(saga)
+
+	// The running saga goes on changing after it is saved.
+	saga.Request.CardNumber = ""
+	saga.Request.Address.Lines[0] = "2 Main St"
+	saga.Modification.Items[0].Quantity = 2
+	saga.CompletedSteps = append(saga.CompletedSteps, "check-order")
+
+	got, _ := store.get(saga.ID)
+	if got.Request.CardNumber == "" || got.Request.Address.Lines[0] != "1 Main St" ||
+		got.Modification.Items[0].Quantity != 1 || len(got.CompletedSteps) != 0 {
+		t.Errorf("stored saga changed with the running one: %+v", got)
+	}
+
+	got.Modification.Items[0].Quantity = 3
+	if again, _ := store.get(saga.ID); again.Modification.Items[0].Quantity != 1 {
+		t.Error("stored saga changed with a copy read from the store")
+	}
+}
@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"saga-order-system/internal/health"
+)
+
+var ErrShuttingDown = errors.New("orchestrator is shutting down")
+
+// Drain stops new sagas from starting and waits for the running ones to end
+// or park. Sagas resumed by callbacks are still run. If ctx expires first,
+// every running saga stalls before its next step, and Drain returns once
+// their current steps have finished. If the orchestrator keeps its sagas in
+// a store file, Recover continues the stalled sagas when it next starts and
+// the parked ones can still be resumed; without one they are lost on exit.
+func (o *Orchestrator) Drain(ctx context.Context) error {
+	o.drainMu.Lock()
+	o.draining = true
+	o.drainMu.Unlock()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for o.inflightSagas() > 0 {
+		select {
+		case <-ctx.Done():
+			o.interrupted.Store(true)
+			for o.inflightSagas() > 0 {
+				<-ticker.C
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// ReadinessChecks are the orchestrator's dependencies: its saga store and
+// the three services. It also reports not ready while draining so that load
+// balancers stop sending new sagas.
+func (o *Orchestrator) ReadinessChecks() map[string]health.Check {
+	return map[string]health.Check{
+		"sagas": func(ctx context.Context) error {
+			return health.Lockable(ctx, &o.sagas.mu)
+		},
+		"order-service":    health.HTTP(o.client, o.orderServiceURL+"/healthz"),
+		"payment-service":  health.HTTP(o.client, o.paymentServiceURL+"/healthz"),
+		"shipping-service": health.HTTP(o.client, o.shippingServiceURL+"/healthz"),
+		"draining": func(ctx context.Context) error {
+			o.drainMu.Lock()
+			defer o.drainMu.Unlock()
+			if o.draining {
+				return ErrShuttingDown
+			}
+			return nil
+		},
+	}
+}
+
+// enter records a saga as running. New sagas are refused once the
+// orchestrator is draining.
+func (o *Orchestrator) enter(starting bool) bool {
+	o.drainMu.Lock()
+	defer o.drainMu.Unlock()
+
+	if starting && o.draining {
+		return false
+	}
+	o.inflight++
+	return true
+}
+
+func (o *Orchestrator) leave() {
+	o.drainMu.Lock()
+	o.inflight--
+	o.drainMu.Unlock()
+}
+
+func (o *Orchestrator) inflightSagas() int {
+	o.drainMu.Lock()
+	defer o.drainMu.Unlock()
+	return o.inflight
+}
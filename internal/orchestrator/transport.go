@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"saga-order-system/internal/clients"
+	"saga-order-system/internal/config"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/rpc"
+)
+
+// orderService, paymentService and shippingService are the calls the sagas
+// make, served by the HTTP clients or the gRPC ones depending on the
+// configured transport.
+type orderService interface {
+	CreateOrder(ctx context.Context, req models.CreateOrderRequest) (*models.OrderResponse, error)
+	GetOrder(ctx context.Context, orderID string) (*models.OrderResponse, error)
+	CancelOrder(ctx context.Context, orderID string) error
+	ModifyOrder(ctx context.Context, orderID string, req models.ModifyOrderRequest) (*models.OrderResponse, error)
+	LockOrder(ctx context.Context, orderID string, req models.LockOrderRequest) (*models.OrderResponse, error)
+	UnlockOrder(ctx context.Context, orderID string, req models.UnlockOrderRequest) error
+	ReserveStock(ctx context.Context, req models.ReserveStockRequest) (*models.InventoryReservation, error)
+	ReleaseStock(ctx context.Context, orderID string) error
+	Restock(ctx context.Context, req models.StockAdjustmentRequest) ([]models.StockLevel, error)
+	DeductStock(ctx context.Context, req models.StockAdjustmentRequest) ([]models.StockLevel, error)
+	CreateReturn(ctx context.Context, req models.CreateReturnRequest) (*models.Return, error)
+	ReceiveReturn(ctx context.Context, returnID string) (*models.Return, error)
+	CompleteReturn(ctx context.Context, returnID string) (*models.Return, error)
+	CancelReturn(ctx context.Context, returnID string) error
+}
+
+type paymentService interface {
+	ProcessPayment(ctx context.Context, req models.ProcessPaymentRequest) (*models.PaymentResponse, error)
+	GetPayment(ctx context.Context, paymentID string) (*models.PaymentResponse, error)
+	RefundPayment(ctx context.Context, req models.RefundPaymentRequest) error
+	VoidPayment(ctx context.Context, req models.VoidPaymentRequest) error
+}
+
+type shippingService interface {
+	ValidateAddress(ctx context.Context, address models.Address) (*models.Address, error)
+	StartShipping(ctx context.Context, req models.StartShippingRequest) (*models.ShippingResponse, error)
+	CancelShipping(ctx context.Context, req models.CancelShippingRequest) error
+	ListOrderShippings(ctx context.Context, orderID string) ([]models.ShippingResponse, error)
+}
+
+// connectServices sets up the clients of the services for cfg's transport.
+func (o *Orchestrator) connectServices(cfg config.Config) error {
+	if cfg.Transport != config.TransportGRPC {
+		o.orders = clients.NewOrderClient(o.orderServiceURL, o.client)
+		o.payments = clients.NewPaymentClient(o.paymentServiceURL, o.client)
+		o.shipping = clients.NewShippingClient(o.shippingServiceURL, o.client)
+		return nil
+	}
+
+	dial := func(service, target string) (*grpc.ClientConn, error) {
+		conn, err := rpc.Dial(target, grpc.WithUnaryInterceptor(o.instrumentCall(service)))
+		if err != nil {
+			return nil, fmt.Errorf("dial %s service: %w", service, err)
+		}
+		o.conns = append(o.conns, conn)
+		return conn, nil
+	}
+
+	timeout := cfg.HTTPTimeout.Std()
+	orderConn, err := dial("order", cfg.Order.GRPCTarget)
+	if err != nil {
+		return err
+	}
+	o.orders = rpc.NewOrderClient(orderConn, timeout)
+
+	paymentConn, err := dial("payment", cfg.Payment.GRPCTarget)
+	if err != nil {
+		return err
+	}
+	o.payments = rpc.NewPaymentClient(paymentConn, timeout)
+
+	shippingConn, err := dial("shipping", cfg.Shipping.GRPCTarget)
+	if err != nil {
+		return err
+	}
+	o.shipping = rpc.NewShippingClient(shippingConn, timeout)
+	return nil
+}
+
+// Close closes the gRPC connections to the services, if any.
+func (o *Orchestrator) Close() error {
+	var errs []error
+	for _, conn := range o.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/tracing"
+)
+
+// client holds what the service clients share: every call is bounded by
+// timeout and carries the request and saga IDs and the trace of its
+// context as metadata.
+type client struct {
+	service string
+	timeout time.Duration
+}
+
+func (c client) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	header := make(http.Header)
+	logging.Propagate(ctx, header)
+	tracing.Propagate(ctx, header)
+	for key, values := range header {
+		for _, value := range values {
+			ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+		}
+	}
+
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// error wraps the error of a failed call the way the HTTP clients do, so
+// that callers can still find the service's *apierror.Error in it.
+func (c client) error(method string, err error) error {
+	return fmt.Errorf("%s service %s: %w", c.service, method, apiError(err))
+}
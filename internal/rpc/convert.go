@@ -0,0 +1,302 @@
+package rpc
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/rpc/pb"
+)
+
+// The converters below map the shared models to the protobuf messages and
+// back. A zero time is sent as an unset timestamp.
+
+func timeToPB(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timeFromPB(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func addressToPB(a models.Address) *pb.Address {
+	return &pb.Address{
+		Recipient:  a.Recipient,
+		Lines:      a.Lines,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Phone:      a.Phone,
+	}
+}
+
+func addressFromPB(a *pb.Address) models.Address {
+	if a == nil {
+		return models.Address{}
+	}
+	return models.Address{
+		Recipient:  a.Recipient,
+		Lines:      a.Lines,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Phone:      a.Phone,
+	}
+}
+
+func optionalAddressToPB(a *models.Address) *pb.Address {
+	if a == nil {
+		return nil
+	}
+	return addressToPB(*a)
+}
+
+func optionalAddressFromPB(a *pb.Address) *models.Address {
+	if a == nil {
+		return nil
+	}
+	address := addressFromPB(a)
+	return &address
+}
+
+func orderItemsToPB(items []models.OrderItem) []*pb.OrderItem {
+	var out []*pb.OrderItem
+	for _, item := range items {
+		out = append(out, &pb.OrderItem{ProductId: item.ProductID, Quantity: int64(item.Quantity), Price: item.Price})
+	}
+	return out
+}
+
+func orderItemsFromPB(items []*pb.OrderItem) []models.OrderItem {
+	var out []models.OrderItem
+	for _, item := range items {
+		out = append(out, models.OrderItem{ProductID: item.ProductId, Quantity: int(item.Quantity), Price: item.Price})
+	}
+	return out
+}
+
+func shipmentItemsToPB(items []models.ShipmentItem) []*pb.ShipmentItem {
+	var out []*pb.ShipmentItem
+	for _, item := range items {
+		out = append(out, &pb.ShipmentItem{ProductId: item.ProductID, Quantity: int64(item.Quantity)})
+	}
+	return out
+}
+
+func shipmentItemsFromPB(items []*pb.ShipmentItem) []models.ShipmentItem {
+	var out []models.ShipmentItem
+	for _, item := range items {
+		out = append(out, models.ShipmentItem{ProductID: item.ProductId, Quantity: int(item.Quantity)})
+	}
+	return out
+}
+
+func orderToPB(o models.OrderResponse) *pb.Order {
+	order := &pb.Order{
+		Id:              o.ID,
+		UserId:          o.UserID,
+		Items:           orderItemsToPB(o.Items),
+		TotalPrice:      o.TotalPrice,
+		ShippingAddress: addressToPB(o.ShippingAddress),
+		Status:          string(o.Status),
+		Version:         int64(o.Version),
+		CreatedAt:       timeToPB(o.CreatedAt),
+	}
+	for _, shipment := range o.Shipments {
+		order.Shipments = append(order.Shipments, &pb.ShipmentUpdate{
+			ShippingId: shipment.ShippingID,
+			Status:     string(shipment.Status),
+			Items:      shipmentItemsToPB(shipment.Items),
+		})
+	}
+	if o.Lock != nil {
+		order.Lock = &pb.OrderLock{ExpiresAt: timeToPB(o.Lock.ExpiresAt)}
+	}
+	return order
+}
+
+func orderFromPB(o *pb.Order) models.OrderResponse {
+	order := models.OrderResponse{
+		ID:              o.Id,
+		UserID:          o.UserId,
+		Items:           orderItemsFromPB(o.Items),
+		TotalPrice:      o.TotalPrice,
+		ShippingAddress: addressFromPB(o.ShippingAddress),
+		Shipments:       []models.ShipmentUpdate{},
+		Status:          models.OrderStatus(o.Status),
+		Version:         int(o.Version),
+		CreatedAt:       timeFromPB(o.CreatedAt),
+	}
+	for _, shipment := range o.Shipments {
+		order.Shipments = append(order.Shipments, models.ShipmentUpdate{
+			ShippingID: shipment.ShippingId,
+			Status:     models.ShippingStatus(shipment.Status),
+			Items:      shipmentItemsFromPB(shipment.Items),
+		})
+	}
+	if o.Lock != nil {
+		order.Lock = &models.OrderLock{ExpiresAt: timeFromPB(o.Lock.ExpiresAt)}
+	}
+	return order
+}
+
+func reservationToPB(r models.InventoryReservation) *pb.InventoryReservation {
+	return &pb.InventoryReservation{
+		OrderId:   r.OrderID,
+		Items:     shipmentItemsToPB(r.Items),
+		CreatedAt: timeToPB(r.CreatedAt),
+	}
+}
+
+func reservationFromPB(r *pb.InventoryReservation) models.InventoryReservation {
+	return models.InventoryReservation{
+		OrderID:   r.OrderId,
+		Items:     shipmentItemsFromPB(r.Items),
+		CreatedAt: timeFromPB(r.CreatedAt),
+	}
+}
+
+func stockLevelsToPB(levels []models.StockLevel) *pb.StockLevels {
+	out := &pb.StockLevels{}
+	for _, level := range levels {
+		out.Levels = append(out.Levels, &pb.StockLevel{
+			ProductId: level.ProductID,
+			Quantity:  int64(level.Quantity),
+			UpdatedAt: timeToPB(level.UpdatedAt),
+		})
+	}
+	return out
+}
+
+func stockLevelsFromPB(levels *pb.StockLevels) []models.StockLevel {
+	out := []models.StockLevel{}
+	for _, level := range levels.Levels {
+		out = append(out, models.StockLevel{
+			ProductID: level.ProductId,
+			Quantity:  int(level.Quantity),
+			UpdatedAt: timeFromPB(level.UpdatedAt),
+		})
+	}
+	return out
+}
+
+func returnToPB(r models.Return) *pb.Return {
+	return &pb.Return{
+		Id:            r.ID,
+		OrderId:       r.OrderID,
+		Items:         shipmentItemsToPB(r.Items),
+		Reason:        r.Reason,
+		RefundAmount:  r.RefundAmount,
+		PickupAddress: addressToPB(r.PickupAddress),
+		Status:        string(r.Status),
+		CreatedAt:     timeToPB(r.CreatedAt),
+		UpdatedAt:     timeToPB(r.UpdatedAt),
+	}
+}
+
+func returnFromPB(r *pb.Return) models.Return {
+	return models.Return{
+		ID:            r.Id,
+		OrderID:       r.OrderId,
+		Items:         shipmentItemsFromPB(r.Items),
+		Reason:        r.Reason,
+		RefundAmount:  r.RefundAmount,
+		PickupAddress: addressFromPB(r.PickupAddress),
+		Status:        models.ReturnStatus(r.Status),
+		CreatedAt:     timeFromPB(r.CreatedAt),
+		UpdatedAt:     timeFromPB(r.UpdatedAt),
+	}
+}
+
+func paymentToPB(p models.PaymentResponse) *pb.Payment {
+	return &pb.Payment{
+		Id:             p.ID,
+		OrderId:        p.OrderID,
+		Amount:         p.Amount,
+		RefundedAmount: p.RefundedAmount,
+		Method:         string(p.Method),
+		Status:         string(p.Status),
+		FailureReason:  p.FailureReason,
+		FraudScore:     int64(p.FraudScore),
+		Version:        int64(p.Version),
+		CreatedAt:      timeToPB(p.CreatedAt),
+	}
+}
+
+func paymentFromPB(p *pb.Payment) models.PaymentResponse {
+	return models.PaymentResponse{
+		ID:             p.Id,
+		OrderID:        p.OrderId,
+		Amount:         p.Amount,
+		RefundedAmount: p.RefundedAmount,
+		Method:         models.PaymentMethodType(p.Method),
+		Status:         models.PaymentStatus(p.Status),
+		FailureReason:  p.FailureReason,
+		FraudScore:     int(p.FraudScore),
+		Version:        int(p.Version),
+		CreatedAt:      timeFromPB(p.CreatedAt),
+	}
+}
+
+func shipmentToPB(s models.ShippingResponse) *pb.Shipment {
+	shipment := &pb.Shipment{
+		Id:             s.ID,
+		OrderId:        s.OrderID,
+		Kind:           string(s.Kind),
+		Items:          shipmentItemsToPB(s.Items),
+		Address:        addressToPB(s.Address),
+		WeightKg:       s.WeightKg,
+		Carrier:        s.Carrier,
+		Service:        s.Service,
+		TrackingNumber: s.TrackingNumber,
+		Cost:           s.Cost,
+		Status:         string(s.Status),
+		Version:        int64(s.Version),
+		CreatedAt:      timeToPB(s.CreatedAt),
+	}
+	for _, event := range s.Events {
+		shipment.Events = append(shipment.Events, &pb.TrackingEvent{
+			Status:      string(event.Status),
+			Location:    event.Location,
+			Description: event.Description,
+			OccurredAt:  timeToPB(event.OccurredAt),
+		})
+	}
+	return shipment
+}
+
+func shipmentFromPB(s *pb.Shipment) models.ShippingResponse {
+	shipment := models.ShippingResponse{
+		ID:             s.Id,
+		OrderID:        s.OrderId,
+		Kind:           models.ShipmentKind(s.Kind),
+		Items:          shipmentItemsFromPB(s.Items),
+		Address:        addressFromPB(s.Address),
+		WeightKg:       s.WeightKg,
+		Carrier:        s.Carrier,
+		Service:        s.Service,
+		TrackingNumber: s.TrackingNumber,
+		Cost:           s.Cost,
+		Status:         models.ShippingStatus(s.Status),
+		Events:         []models.TrackingEvent{},
+		Version:        int(s.Version),
+		CreatedAt:      timeFromPB(s.CreatedAt),
+	}
+	for _, event := range s.Events {
+		shipment.Events = append(shipment.Events, models.TrackingEvent{
+			Status:      models.ShippingStatus(event.Status),
+			Location:    event.Location,
+			Description: event.Description,
+			OccurredAt:  timeFromPB(event.OccurredAt),
+		})
+	}
+	return shipment
+}
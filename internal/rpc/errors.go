@@ -0,0 +1,113 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/rpc/pb"
+)
+
+// statusError turns the error a service answered with into a gRPC status
+// with the code closest to its apierror code, carrying the whole error as a
+// pb.Error detail. Errors that are not an *apierror.Error are internal.
+func statusError(err error) error {
+	var apiErr *apierror.Error
+	if !errors.As(err, &apiErr) {
+		apiErr = apierror.Internal(err.Error())
+	}
+
+	detail := &pb.Error{
+		Code:       string(apiErr.Code),
+		Message:    apiErr.Message,
+		Retryable:  apiErr.Retryable,
+		HttpStatus: int32(apiErr.Status),
+	}
+	if len(apiErr.Details) > 0 {
+		if details, err := detailsToStruct(apiErr.Details); err == nil {
+			detail.Details = details
+		}
+	}
+
+	st := status.New(grpcCode(apiErr.Code), apiErr.Message)
+	if withDetail, err := st.WithDetails(detail); err == nil {
+		st = withDetail
+	}
+	return st.Err()
+}
+
+// detailsToStruct converts error details, which may hold models, through
+// JSON into the values a Struct can hold.
+func detailsToStruct(details map[string]interface{}) (*structpb.Struct, error) {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(values)
+}
+
+// apiError recovers the *apierror.Error a call was answered with. Other
+// failures, e.g. a server that cannot be reached, are returned unchanged,
+// as the HTTP clients return transport errors.
+func apiError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	for _, d := range st.Details() {
+		detail, ok := d.(*pb.Error)
+		if !ok {
+			continue
+		}
+		apiErr := &apierror.Error{
+			Status:    int(detail.HttpStatus),
+			Code:      apierror.Code(detail.Code),
+			Message:   detail.Message,
+			Retryable: detail.Retryable,
+		}
+		if detail.Details != nil {
+			apiErr.Details = detail.Details.AsMap()
+		}
+		return apiErr
+	}
+	return err
+}
+
+func grpcCode(code apierror.Code) codes.Code {
+	switch code {
+	case apierror.CodeInvalidRequest, apierror.CodeValidationFailed:
+		return codes.InvalidArgument
+	case apierror.CodeNotFound:
+		return codes.NotFound
+	case apierror.CodeConflict, apierror.CodePreconditionFailed, apierror.CodeInsufficientStock, apierror.CodePaymentDeclined:
+		return codes.FailedPrecondition
+	case apierror.CodePaymentRejected:
+		return codes.PermissionDenied
+	case apierror.CodeLocked:
+		return codes.Aborted
+	case apierror.CodeNotImplemented:
+		return codes.Unimplemented
+	case apierror.CodeUpstreamFailed, apierror.CodeUnavailable:
+		return codes.Unavailable
+	case apierror.CodeUpstreamTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
+
+// serverFault reports whether a call failing with code failed through the
+// server's fault, as a 5xx response does.
+func serverFault(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unimplemented, codes.Unavailable, codes.DeadlineExceeded, codes.DataLoss:
+		return true
+	}
+	return false
+}
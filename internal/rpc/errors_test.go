@@ -0,0 +1,46 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"saga-order-system/internal/apierror"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+		want apierror.Code
+	}{
+		{"not found", apierror.NotFound("Order o1 not found"), codes.NotFound, apierror.CodeNotFound},
+		{"validation", apierror.ValidationFailed("bad address"), codes.InvalidArgument, apierror.CodeValidationFailed},
+		{"precondition", apierror.New(412, apierror.CodePreconditionFailed, "stale").WithDetail("version", 3), codes.FailedPrecondition, apierror.CodePreconditionFailed},
+		{"locked", apierror.New(423, apierror.CodeLocked, "locked"), codes.Aborted, apierror.CodeLocked},
+		{"plain error", errors.New("boom"), codes.Internal, apierror.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := statusError(tt.err)
+			if got := status.Code(err); got != tt.code {
+				t.Errorf("code = %s, want %s", got, tt.code)
+			}
+
+			var apiErr *apierror.Error
+			if !errors.As(apiError(err), &apiErr) {
+				t.Fatalf("apiError(%v) is not an *apierror.Error", err)
+			}
+			if apiErr.Code != tt.want {
+				t.Errorf("apierror code = %s, want %s", apiErr.Code, tt.want)
+			}
+			var orig *apierror.Error
+			if errors.As(tt.err, &orig) && apiErr.Status != orig.Status {
+				t.Errorf("status = %d, want %d", apiErr.Status, orig.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/rpc/pb"
+	"saga-order-system/internal/services/order"
+)
+
+type orderServer struct {
+	pb.UnimplementedOrderServiceServer
+	service *order.Service
+}
+
+// NewOrderServer serves the order service's RPCs with service. Calls take no
+// version precondition, and a saga's calls are made on behalf of the saga
+// named in their metadata, like its HTTP requests.
+func NewOrderServer(service *order.Service) pb.OrderServiceServer {
+	return &orderServer{service: service}
+}
+
+func (s *orderServer) CreateOrder(ctx context.Context, in *pb.CreateOrderRequest) (*pb.Order, error) {
+	req := models.CreateOrderRequest{
+		UserID:          in.UserId,
+		Items:           orderItemsFromPB(in.Items),
+		ShippingAddress: addressFromPB(in.ShippingAddress),
+	}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	return orderToPB(s.service.CreateOrder(req, logging.SagaID(ctx))), nil
+}
+
+func (s *orderServer) GetOrder(ctx context.Context, in *pb.GetOrderRequest) (*pb.Order, error) {
+	return s.order(s.service.GetOrder(in.OrderId))
+}
+
+func (s *orderServer) CancelOrder(ctx context.Context, in *pb.CancelOrderRequest) (*pb.Acknowledgement, error) {
+	if _, err := s.service.CancelOrder(in.OrderId, logging.SagaID(ctx), ""); err != nil {
+		return nil, statusError(err)
+	}
+	return acknowledgement("Order %s cancelled successfully", in.OrderId), nil
+}
+
+func (s *orderServer) ModifyOrder(ctx context.Context, in *pb.ModifyOrderRequest) (*pb.Order, error) {
+	req := models.ModifyOrderRequest{
+		Items:           orderItemsFromPB(in.Items),
+		ShippingAddress: optionalAddressFromPB(in.ShippingAddress),
+	}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	return s.order(s.service.ModifyOrder(in.OrderId, req, logging.SagaID(ctx), ""))
+}
+
+func (s *orderServer) LockOrder(ctx context.Context, in *pb.LockOrderRequest) (*pb.Order, error) {
+	req := models.LockOrderRequest{SagaID: in.SagaId, TTLSeconds: int(in.TtlSeconds)}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	return s.order(s.service.LockOrder(in.OrderId, req, ""))
+}
+
+func (s *orderServer) UnlockOrder(ctx context.Context, in *pb.UnlockOrderRequest) (*pb.Order, error) {
+	req := models.UnlockOrderRequest{SagaID: in.SagaId}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	return s.order(s.service.UnlockOrder(in.OrderId, req, ""))
+}
+
+func (s *orderServer) order(order models.OrderResponse, err error) (*pb.Order, error) {
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return orderToPB(order), nil
+}
+
+func (s *orderServer) ReserveStock(ctx context.Context, in *pb.ReserveStockRequest) (*pb.InventoryReservation, error) {
+	req := models.ReserveStockRequest{OrderID: in.OrderId, Items: shipmentItemsFromPB(in.Items)}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	reservation, _, err := s.service.ReserveStock(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return reservationToPB(reservation), nil
+}
+
+func (s *orderServer) ReleaseStock(ctx context.Context, in *pb.ReleaseStockRequest) (*pb.Acknowledgement, error) {
+	if !s.service.ReleaseStock(in.OrderId) {
+		return acknowledgement("No stock reserved for order %s", in.OrderId), nil
+	}
+	return acknowledgement("Stock reserved for order %s released", in.OrderId), nil
+}
+
+func (s *orderServer) Restock(ctx context.Context, in *pb.StockAdjustmentRequest) (*pb.StockLevels, error) {
+	req := models.StockAdjustmentRequest{
+		Items:     shipmentItemsFromPB(in.Items),
+		Returned:  in.Returned,
+		Reference: in.Reference,
+	}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	return stockLevelsToPB(s.service.Restock(req)), nil
+}
+
+func (s *orderServer) DeductStock(ctx context.Context, in *pb.StockAdjustmentRequest) (*pb.StockLevels, error) {
+	req := models.StockAdjustmentRequest{
+		Items:     shipmentItemsFromPB(in.Items),
+		Returned:  in.Returned,
+		Reference: in.Reference,
+	}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	levels, err := s.service.DeductStock(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return stockLevelsToPB(levels), nil
+}
+
+func (s *orderServer) CreateReturn(ctx context.Context, in *pb.CreateReturnRequest) (*pb.Return, error) {
+	req := models.CreateReturnRequest{
+		OrderID: in.OrderId,
+		Items:   shipmentItemsFromPB(in.Items),
+		Reason:  in.Reason,
+	}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	return s.ret(s.service.CreateReturn(req))
+}
+
+func (s *orderServer) ReceiveReturn(ctx context.Context, in *pb.ReturnRequest) (*pb.Return, error) {
+	return s.ret(s.service.ReceiveReturn(in.ReturnId))
+}
+
+func (s *orderServer) CompleteReturn(ctx context.Context, in *pb.ReturnRequest) (*pb.Return, error) {
+	return s.ret(s.service.CompleteReturn(in.ReturnId))
+}
+
+func (s *orderServer) CancelReturn(ctx context.Context, in *pb.ReturnRequest) (*pb.Acknowledgement, error) {
+	if err := s.service.CancelReturn(in.ReturnId); err != nil {
+		return nil, statusError(err)
+	}
+	return acknowledgement("Return %s cancelled successfully", in.ReturnId), nil
+}
+
+func (s *orderServer) ret(ret models.Return, err error) (*pb.Return, error) {
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return returnToPB(ret), nil
+}
+
+// OrderClient calls the order service over gRPC. It has the methods of
+// clients.OrderClient that the orchestrator uses.
+type OrderClient struct {
+	client
+	rpc pb.OrderServiceClient
+}
+
+func NewOrderClient(conn grpc.ClientConnInterface, timeout time.Duration) *OrderClient {
+	return &OrderClient{client{service: "order", timeout: timeout}, pb.NewOrderServiceClient(conn)}
+}
+
+func (c *OrderClient) CreateOrder(ctx context.Context, req models.CreateOrderRequest) (*models.OrderResponse, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	order, err := c.rpc.CreateOrder(ctx, &pb.CreateOrderRequest{
+		UserId:          req.UserID,
+		Items:           orderItemsToPB(req.Items),
+		ShippingAddress: addressToPB(req.ShippingAddress),
+	})
+	return c.order("CreateOrder", order, err)
+}
+
+func (c *OrderClient) GetOrder(ctx context.Context, orderID string) (*models.OrderResponse, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	order, err := c.rpc.GetOrder(ctx, &pb.GetOrderRequest{OrderId: orderID})
+	return c.order("GetOrder", order, err)
+}
+
+func (c *OrderClient) CancelOrder(ctx context.Context, orderID string) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	if _, err := c.rpc.CancelOrder(ctx, &pb.CancelOrderRequest{OrderId: orderID}); err != nil {
+		return c.error("CancelOrder", err)
+	}
+	return nil
+}
+
+func (c *OrderClient) ModifyOrder(ctx context.Context, orderID string, req models.ModifyOrderRequest) (*models.OrderResponse, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	order, err := c.rpc.ModifyOrder(ctx, &pb.ModifyOrderRequest{
+		OrderId:         orderID,
+		Items:           orderItemsToPB(req.Items),
+		ShippingAddress: optionalAddressToPB(req.ShippingAddress),
+	})
+	return c.order("ModifyOrder", order, err)
+}
+
+func (c *OrderClient) LockOrder(ctx context.Context, orderID string, req models.LockOrderRequest) (*models.OrderResponse, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	order, err := c.rpc.LockOrder(ctx, &pb.LockOrderRequest{
+		OrderId:    orderID,
+		SagaId:     req.SagaID,
+		TtlSeconds: int64(req.TTLSeconds),
+	})
+	return c.order("LockOrder", order, err)
+}
+
+func (c *OrderClient) UnlockOrder(ctx context.Context, orderID string, req models.UnlockOrderRequest) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	if _, err := c.rpc.UnlockOrder(ctx, &pb.UnlockOrderRequest{OrderId: orderID, SagaId: req.SagaID}); err != nil {
+		return c.error("UnlockOrder", err)
+	}
+	return nil
+}
+
+func (c *OrderClient) order(method string, order *pb.Order, err error) (*models.OrderResponse, error) {
+	if err != nil {
+		return nil, c.error(method, err)
+	}
+	resp := orderFromPB(order)
+	return &resp, nil
+}
+
+// ReserveStock holds stock for an order. Reserving again for the same order
+// returns the existing reservation.
+func (c *OrderClient) ReserveStock(ctx context.Context, req models.ReserveStockRequest) (*models.InventoryReservation, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	reservation, err := c.rpc.ReserveStock(ctx, &pb.ReserveStockRequest{
+		OrderId: req.OrderID,
+		Items:   shipmentItemsToPB(req.Items),
+	})
+	if err != nil {
+		return nil, c.error("ReserveStock", err)
+	}
+	resp := reservationFromPB(reservation)
+	return &resp, nil
+}
+
+func (c *OrderClient) ReleaseStock(ctx context.Context, orderID string) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	if _, err := c.rpc.ReleaseStock(ctx, &pb.ReleaseStockRequest{OrderId: orderID}); err != nil {
+		return c.error("ReleaseStock", err)
+	}
+	return nil
+}
+
+func (c *OrderClient) Restock(ctx context.Context, req models.StockAdjustmentRequest) ([]models.StockLevel, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	levels, err := c.rpc.Restock(ctx, &pb.StockAdjustmentRequest{
+		Items:     shipmentItemsToPB(req.Items),
+		Returned:  req.Returned,
+		Reference: req.Reference,
+	})
+	if err != nil {
+		return nil, c.error("Restock", err)
+	}
+	return stockLevelsFromPB(levels), nil
+}
+
+func (c *OrderClient) DeductStock(ctx context.Context, req models.StockAdjustmentRequest) ([]models.StockLevel, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	levels, err := c.rpc.DeductStock(ctx, &pb.StockAdjustmentRequest{
+		Items:     shipmentItemsToPB(req.Items),
+		Returned:  req.Returned,
+		Reference: req.Reference,
+	})
+	if err != nil {
+		return nil, c.error("DeductStock", err)
+	}
+	return stockLevelsFromPB(levels), nil
+}
+
+func (c *OrderClient) CreateReturn(ctx context.Context, req models.CreateReturnRequest) (*models.Return, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	ret, err := c.rpc.CreateReturn(ctx, &pb.CreateReturnRequest{
+		OrderId: req.OrderID,
+		Items:   shipmentItemsToPB(req.Items),
+		Reason:  req.Reason,
+	})
+	return c.ret("CreateReturn", ret, err)
+}
+
+func (c *OrderClient) ReceiveReturn(ctx context.Context, returnID string) (*models.Return, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	ret, err := c.rpc.ReceiveReturn(ctx, &pb.ReturnRequest{ReturnId: returnID})
+	return c.ret("ReceiveReturn", ret, err)
+}
+
+func (c *OrderClient) CompleteReturn(ctx context.Context, returnID string) (*models.Return, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	ret, err := c.rpc.CompleteReturn(ctx, &pb.ReturnRequest{ReturnId: returnID})
+	return c.ret("CompleteReturn", ret, err)
+}
+
+func (c *OrderClient) CancelReturn(ctx context.Context, returnID string) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	if _, err := c.rpc.CancelReturn(ctx, &pb.ReturnRequest{ReturnId: returnID}); err != nil {
+		return c.error("CancelReturn", err)
+	}
+	return nil
+}
+
+func (c *OrderClient) ret(method string, ret *pb.Return, err error) (*models.Return, error) {
+	if err != nil {
+		return nil, c.error(method, err)
+	}
+	resp := returnFromPB(ret)
+	return &resp, nil
+}
@@ -0,0 +1,140 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/rpc/pb"
+	"saga-order-system/internal/services/payment"
+)
+
+type paymentServer struct {
+	pb.UnimplementedPaymentServiceServer
+	service *payment.Service
+}
+
+// NewPaymentServer serves the payment service's RPCs with service.
+func NewPaymentServer(service *payment.Service) pb.PaymentServiceServer {
+	return &paymentServer{service: service}
+}
+
+func (s *paymentServer) ProcessPayment(ctx context.Context, in *pb.ProcessPaymentRequest) (*pb.Payment, error) {
+	req := models.ProcessPaymentRequest{
+		OrderID:         in.OrderId,
+		UserID:          in.UserId,
+		Amount:          in.Amount,
+		PaymentMethodID: in.PaymentMethodId,
+		CardNumber:      in.CardNumber,
+		BillingAddress:  optionalAddressFromPB(in.BillingAddress),
+		ShippingAddress: optionalAddressFromPB(in.ShippingAddress),
+		CallbackURL:     in.CallbackUrl,
+	}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	payment, err := s.service.ProcessPayment(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return paymentToPB(payment), nil
+}
+
+func (s *paymentServer) GetPayment(ctx context.Context, in *pb.GetPaymentRequest) (*pb.Payment, error) {
+	payment, err := s.service.GetPayment(in.PaymentId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return paymentToPB(payment), nil
+}
+
+func (s *paymentServer) RefundPayment(ctx context.Context, in *pb.RefundPaymentRequest) (*pb.Acknowledgement, error) {
+	req := models.RefundPaymentRequest{OrderID: in.OrderId, Amount: in.Amount, Reference: in.Reference}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	refunded, err := s.service.RefundPayment(req, "")
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return acknowledgement("Refunded %.2f of payment for order %s successfully", refunded, in.OrderId), nil
+}
+
+func (s *paymentServer) VoidPayment(ctx context.Context, in *pb.VoidPaymentRequest) (*pb.Acknowledgement, error) {
+	req := models.VoidPaymentRequest{OrderID: in.OrderId}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	voided := s.service.VoidPayment(in.OrderId)
+	if voided == 0 {
+		return acknowledgement("No payment held for order %s", in.OrderId), nil
+	}
+	return acknowledgement("%d payment(s) held for order %s voided", voided, in.OrderId), nil
+}
+
+// PaymentClient calls the payment service over gRPC.
+type PaymentClient struct {
+	client
+	rpc pb.PaymentServiceClient
+}
+
+func NewPaymentClient(conn grpc.ClientConnInterface, timeout time.Duration) *PaymentClient {
+	return &PaymentClient{client{service: "payment", timeout: timeout}, pb.NewPaymentServiceClient(conn)}
+}
+
+// ProcessPayment charges for an order. A payment held for fraud review comes
+// back with status PENDING_REVIEW, and the outcome is later posted to the
+// request's CallbackURL.
+func (c *PaymentClient) ProcessPayment(ctx context.Context, req models.ProcessPaymentRequest) (*models.PaymentResponse, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	payment, err := c.rpc.ProcessPayment(ctx, &pb.ProcessPaymentRequest{
+		OrderId:         req.OrderID,
+		UserId:          req.UserID,
+		Amount:          req.Amount,
+		PaymentMethodId: req.PaymentMethodID,
+		CardNumber:      req.CardNumber,
+		BillingAddress:  optionalAddressToPB(req.BillingAddress),
+		ShippingAddress: optionalAddressToPB(req.ShippingAddress),
+		CallbackUrl:     req.CallbackURL,
+	})
+	if err != nil {
+		return nil, c.error("ProcessPayment", err)
+	}
+	resp := paymentFromPB(payment)
+	return &resp, nil
+}
+
+func (c *PaymentClient) GetPayment(ctx context.Context, paymentID string) (*models.PaymentResponse, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	payment, err := c.rpc.GetPayment(ctx, &pb.GetPaymentRequest{PaymentId: paymentID})
+	if err != nil {
+		return nil, c.error("GetPayment", err)
+	}
+	resp := paymentFromPB(payment)
+	return &resp, nil
+}
+
+func (c *PaymentClient) RefundPayment(ctx context.Context, req models.RefundPaymentRequest) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	if _, err := c.rpc.RefundPayment(ctx, &pb.RefundPaymentRequest{
+		OrderId:   req.OrderID,
+		Amount:    req.Amount,
+		Reference: req.Reference,
+	}); err != nil {
+		return c.error("RefundPayment", err)
+	}
+	return nil
+}
+
+func (c *PaymentClient) VoidPayment(ctx context.Context, req models.VoidPaymentRequest) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	if _, err := c.rpc.VoidPayment(ctx, &pb.VoidPaymentRequest{OrderId: req.OrderID}); err != nil {
+		return c.error("VoidPayment", err)
+	}
+	return nil
+}
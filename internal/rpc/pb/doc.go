@@ -0,0 +1,5 @@
+// Package pb holds the messages and gRPC stubs generated from the .proto
+// files in this directory.
+package pb
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative common.proto order.proto payment.proto shipping.proto
@@ -0,0 +1,226 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: shipping.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ShippingService_ValidateAddress_FullMethodName    = "/saga.v1.ShippingService/ValidateAddress"
+	ShippingService_StartShipping_FullMethodName      = "/saga.v1.ShippingService/StartShipping"
+	ShippingService_CancelShipping_FullMethodName     = "/saga.v1.ShippingService/CancelShipping"
+	ShippingService_ListOrderShippings_FullMethodName = "/saga.v1.ShippingService/ListOrderShippings"
+)
+
+// ShippingServiceClient is the client API for ShippingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ShippingServiceClient interface {
+	// ValidateAddress returns the address in its normalized form.
+	ValidateAddress(ctx context.Context, in *Address, opts ...grpc.CallOption) (*Address, error)
+	StartShipping(ctx context.Context, in *StartShippingRequest, opts ...grpc.CallOption) (*Shipment, error)
+	// CancelShipping fails with FAILED_PRECONDITION once a shipment has been
+	// picked up.
+	CancelShipping(ctx context.Context, in *CancelShippingRequest, opts ...grpc.CallOption) (*Acknowledgement, error)
+	ListOrderShippings(ctx context.Context, in *ListOrderShippingsRequest, opts ...grpc.CallOption) (*Shipments, error)
+}
+
+type shippingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShippingServiceClient(cc grpc.ClientConnInterface) ShippingServiceClient {
+	return &shippingServiceClient{cc}
+}
+
+func (c *shippingServiceClient) ValidateAddress(ctx context.Context, in *Address, opts ...grpc.CallOption) (*Address, error) {
+	out := new(Address)
+	err := c.cc.Invoke(ctx, ShippingService_ValidateAddress_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shippingServiceClient) StartShipping(ctx context.Context, in *StartShippingRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShippingService_StartShipping_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shippingServiceClient) CancelShipping(ctx context.Context, in *CancelShippingRequest, opts ...grpc.CallOption) (*Acknowledgement, error) {
+	out := new(Acknowledgement)
+	err := c.cc.Invoke(ctx, ShippingService_CancelShipping_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shippingServiceClient) ListOrderShippings(ctx context.Context, in *ListOrderShippingsRequest, opts ...grpc.CallOption) (*Shipments, error) {
+	out := new(Shipments)
+	err := c.cc.Invoke(ctx, ShippingService_ListOrderShippings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ShippingServiceServer is the server API for ShippingService service.
+// All implementations must embed UnimplementedShippingServiceServer
+// for forward compatibility
+type ShippingServiceServer interface {
+	// ValidateAddress returns the address in its normalized form.
+	ValidateAddress(context.Context, *Address) (*Address, error)
+	StartShipping(context.Context, *StartShippingRequest) (*Shipment, error)
+	// CancelShipping fails with FAILED_PRECONDITION once a shipment has been
+	// picked up.
+	CancelShipping(context.Context, *CancelShippingRequest) (*Acknowledgement, error)
+	ListOrderShippings(context.Context, *ListOrderShippingsRequest) (*Shipments, error)
+	mustEmbedUnimplementedShippingServiceServer()
+}
+
+// UnimplementedShippingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedShippingServiceServer struct {
+}
+
+func (UnimplementedShippingServiceServer) ValidateAddress(context.Context, *Address) (*Address, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateAddress not implemented")
+}
+func (UnimplementedShippingServiceServer) StartShipping(context.Context, *StartShippingRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartShipping not implemented")
+}
+func (UnimplementedShippingServiceServer) CancelShipping(context.Context, *CancelShippingRequest) (*Acknowledgement, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelShipping not implemented")
+}
+func (UnimplementedShippingServiceServer) ListOrderShippings(context.Context, *ListOrderShippingsRequest) (*Shipments, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrderShippings not implemented")
+}
+func (UnimplementedShippingServiceServer) mustEmbedUnimplementedShippingServiceServer() {}
+
+// UnsafeShippingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ShippingServiceServer will
+// result in compilation errors.
+type UnsafeShippingServiceServer interface {
+	mustEmbedUnimplementedShippingServiceServer()
+}
+
+func RegisterShippingServiceServer(s grpc.ServiceRegistrar, srv ShippingServiceServer) {
+	s.RegisterService(&ShippingService_ServiceDesc, srv)
+}
+
+func _ShippingService_ValidateAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Address)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShippingServiceServer).ValidateAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShippingService_ValidateAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShippingServiceServer).ValidateAddress(ctx, req.(*Address))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShippingService_StartShipping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartShippingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShippingServiceServer).StartShipping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShippingService_StartShipping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShippingServiceServer).StartShipping(ctx, req.(*StartShippingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShippingService_CancelShipping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelShippingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShippingServiceServer).CancelShipping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShippingService_CancelShipping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShippingServiceServer).CancelShipping(ctx, req.(*CancelShippingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShippingService_ListOrderShippings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrderShippingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShippingServiceServer).ListOrderShippings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShippingService_ListOrderShippings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShippingServiceServer).ListOrderShippings(ctx, req.(*ListOrderShippingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ShippingService_ServiceDesc is the grpc.ServiceDesc for ShippingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ShippingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "saga.v1.ShippingService",
+	HandlerType: (*ShippingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidateAddress",
+			Handler:    _ShippingService_ValidateAddress_Handler,
+		},
+		{
+			MethodName: "StartShipping",
+			Handler:    _ShippingService_StartShipping_Handler,
+		},
+		{
+			MethodName: "CancelShipping",
+			Handler:    _ShippingService_CancelShipping_Handler,
+		},
+		{
+			MethodName: "ListOrderShippings",
+			Handler:    _ShippingService_ListOrderShippings_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "shipping.proto",
+}
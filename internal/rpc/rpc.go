@@ -0,0 +1,54 @@
+// Package rpc carries the service-to-service calls of the order, payment
+// and shipping services over gRPC, as an alternative to the HTTP clients.
+// The gRPC servers call the same service methods as the HTTP handlers, and
+// the clients have the same methods as the clients package.
+package rpc
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"saga-order-system/internal/logging"
+)
+
+// Serve serves srv on addr in the background. The returned function stops
+// the server, letting in-flight calls finish until ctx expires and cutting
+// them off after that. It can be passed to server.Run as its drain, so that
+// the gRPC server is stopped along with the HTTP one.
+func Serve(addr string, srv *grpc.Server) (func(ctx context.Context) error, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if err := srv.Serve(listener); err != nil {
+			logging.Default().Error("gRPC server stopped", "addr", addr, "error", err)
+		}
+	}()
+	stop := func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			srv.Stop()
+			return ctx.Err()
+		}
+	}
+	return stop, nil
+}
+
+// Dial connects to the gRPC server at target. Like the HTTP services, the
+// servers run without TLS.
+func Dial(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	return grpc.Dial(target, opts...)
+}
@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/metrics"
+	"saga-order-system/internal/rpc/pb"
+	"saga-order-system/internal/tracing"
+)
+
+// ServerInterceptor does for every call what the HTTP middleware does for a
+// request: it gives the call a logger carrying the request and saga IDs
+// from its metadata, continues the caller's trace, counts and times the
+// call, and logs it once it has been handled.
+func ServerInterceptor(base *logging.Logger, tracer *tracing.Tracer, registry *metrics.Registry) grpc.UnaryServerInterceptor {
+	calls := registry.Counter("grpc_server_handled_total", "gRPC calls handled.", "method", "code")
+	duration := registry.Histogram("grpc_server_handling_seconds", "Time spent handling gRPC calls.", metrics.DefaultBuckets, "method")
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		requestID := first(md, "x-request-id")
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		sagaID := first(md, "x-saga-id")
+		logger := base.With("request_id", requestID)
+		if sagaID != "" {
+			logger = logger.With("saga_id", sagaID)
+		}
+		ctx = logging.NewContext(logging.ContextWithIDs(ctx, requestID, sagaID), logger)
+
+		parent, _ := tracing.ParseTraceparent(first(md, "traceparent"))
+		span := tracer.StartWithParent(parent, info.FullMethod)
+		ctx = tracing.ContextWithSpan(ctx, span)
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		span.SetAttribute("rpc.method", info.FullMethod)
+		span.SetAttribute("rpc.grpc.status_code", code.String())
+		var spanErr error
+		if serverFault(code) {
+			spanErr = err
+		}
+		span.Finish(spanErr)
+
+		calls.Inc(info.FullMethod, code.String())
+		duration.Observe(time.Since(start).Seconds(), info.FullMethod)
+
+		level := logging.LevelInfo
+		switch {
+		case serverFault(code):
+			level = logging.LevelError
+		case err != nil:
+			level = logging.LevelWarn
+		}
+		args := []interface{}{
+			"method", info.FullMethod,
+			"code", code.String(),
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if err != nil {
+			args = append(args, "error", status.Convert(err).Message())
+		}
+		logger.Log(level, "Call handled", args...)
+		return resp, err
+	}
+}
+
+// validate checks a request against its binding tags, as the HTTP handlers
+// do when binding the request body.
+func validate(req interface{}) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return statusError(apierror.InvalidRequest(err.Error()))
+	}
+	return nil
+}
+
+// acknowledgement confirms what a call did, in the words of the matching
+// HTTP route's message.
+func acknowledgement(format string, args ...interface{}) *pb.Acknowledgement {
+	return &pb.Acknowledgement{Message: fmt.Sprintf(format, args...)}
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/rpc/pb"
+	"saga-order-system/internal/services/shipping"
+)
+
+type shippingServer struct {
+	pb.UnimplementedShippingServiceServer
+	service *shipping.Service
+}
+
+// NewShippingServer serves the shipping service's RPCs with service.
+func NewShippingServer(service *shipping.Service) pb.ShippingServiceServer {
+	return &shippingServer{service: service}
+}
+
+func (s *shippingServer) ValidateAddress(ctx context.Context, in *pb.Address) (*pb.Address, error) {
+	address := addressFromPB(in)
+	if err := validate(address); err != nil {
+		return nil, err
+	}
+	normalized, err := s.service.ValidateAddress(address)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return addressToPB(normalized), nil
+}
+
+func (s *shippingServer) StartShipping(ctx context.Context, in *pb.StartShippingRequest) (*pb.Shipment, error) {
+	req := models.StartShippingRequest{
+		OrderID:     in.OrderId,
+		Kind:        models.ShipmentKind(in.Kind),
+		CallbackURL: in.CallbackUrl,
+		Items:       shipmentItemsFromPB(in.Items),
+		Address:     addressFromPB(in.Address),
+		WeightKg:    in.WeightKg,
+		Carrier:     in.Carrier,
+		Service:     in.Service,
+		Selection:   models.CarrierSelection(in.Selection),
+	}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	shipment, err := s.service.StartShipping(ctx, req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return shipmentToPB(shipment), nil
+}
+
+func (s *shippingServer) CancelShipping(ctx context.Context, in *pb.CancelShippingRequest) (*pb.Acknowledgement, error) {
+	req := models.CancelShippingRequest{OrderID: in.OrderId, ShippingID: in.ShippingId}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	cancelled, err := s.service.CancelShipping(ctx, req, "")
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if cancelled == 0 {
+		return acknowledgement("No shipping found for order %s", in.OrderId), nil
+	}
+	return acknowledgement("%d shipping(s) for order %s cancelled successfully", cancelled, in.OrderId), nil
+}
+
+func (s *shippingServer) ListOrderShippings(ctx context.Context, in *pb.ListOrderShippingsRequest) (*pb.Shipments, error) {
+	out := &pb.Shipments{}
+	for _, shipment := range s.service.ListOrderShippings(in.OrderId) {
+		out.Shipments = append(out.Shipments, shipmentToPB(shipment))
+	}
+	return out, nil
+}
+
+// ShippingClient calls the shipping service over gRPC.
+type ShippingClient struct {
+	client
+	rpc pb.ShippingServiceClient
+}
+
+func NewShippingClient(conn grpc.ClientConnInterface, timeout time.Duration) *ShippingClient {
+	return &ShippingClient{client{service: "shipping", timeout: timeout}, pb.NewShippingServiceClient(conn)}
+}
+
+// ValidateAddress returns the address in its normalized form.
+func (c *ShippingClient) ValidateAddress(ctx context.Context, address models.Address) (*models.Address, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	normalized, err := c.rpc.ValidateAddress(ctx, addressToPB(address))
+	if err != nil {
+		return nil, c.error("ValidateAddress", err)
+	}
+	resp := addressFromPB(normalized)
+	return &resp, nil
+}
+
+func (c *ShippingClient) StartShipping(ctx context.Context, req models.StartShippingRequest) (*models.ShippingResponse, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	shipment, err := c.rpc.StartShipping(ctx, &pb.StartShippingRequest{
+		OrderId:     req.OrderID,
+		Kind:        string(req.Kind),
+		CallbackUrl: req.CallbackURL,
+		Items:       shipmentItemsToPB(req.Items),
+		Address:     addressToPB(req.Address),
+		WeightKg:    req.WeightKg,
+		Carrier:     req.Carrier,
+		Service:     req.Service,
+		Selection:   string(req.Selection),
+	})
+	if err != nil {
+		return nil, c.error("StartShipping", err)
+	}
+	resp := shipmentFromPB(shipment)
+	return &resp, nil
+}
+
+// CancelShipping fails with status 409 Conflict when a shipment has already
+// been picked up.
+func (c *ShippingClient) CancelShipping(ctx context.Context, req models.CancelShippingRequest) error {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	if _, err := c.rpc.CancelShipping(ctx, &pb.CancelShippingRequest{OrderId: req.OrderID, ShippingId: req.ShippingID}); err != nil {
+		return c.error("CancelShipping", err)
+	}
+	return nil
+}
+
+func (c *ShippingClient) ListOrderShippings(ctx context.Context, orderID string) ([]models.ShippingResponse, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	shipments, err := c.rpc.ListOrderShippings(ctx, &pb.ListOrderShippingsRequest{OrderId: orderID})
+	if err != nil {
+		return nil, c.error("ListOrderShippings", err)
+	}
+	out := []models.ShippingResponse{}
+	for _, shipment := range shipments.Shipments {
+		out = append(out, shipmentFromPB(shipment))
+	}
+	return out, nil
+}
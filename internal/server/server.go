@@ -0,0 +1,68 @@
+// Package server runs a binary's HTTP server until it is told to stop.
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"saga-order-system/internal/logging"
+)
+
+// Run serves handler on addr until the process receives SIGINT or SIGTERM.
+// It then calls drain, if given, while still serving, and finally shuts the
+// server down, letting in-flight requests finish. Each of the two phases is
+// bounded by timeout. If the server fails instead, drain is still called
+// before Run returns the error.
+func Run(addr string, handler http.Handler, timeout time.Duration, drain func(ctx context.Context) error) error {
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-serveErr:
+		runDrain(drain, timeout)
+		return err
+	case sig := <-signals:
+		logging.Default().Info("Shutting down", "signal", sig)
+	}
+
+	runDrain(drain, timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func runDrain(drain func(ctx context.Context) error, timeout time.Duration) {
+	if drain == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := drain(ctx); err != nil {
+		logging.Default().Warn("Drain did not complete", "error", err)
+	}
+}
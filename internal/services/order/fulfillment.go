@@ -0,0 +1,126 @@
+package order
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/etag"
+	"saga-order-system/internal/models"
+)
+
+// UpdateShipment is called by the shipping service whenever one of the
+// order's shipments changes status. The order status is derived from all
+// of its shipments unless the order has been cancelled.
+func (s *Service) UpdateShipment(orderID string, update models.ShipmentUpdate, ifMatch etag.Precondition) (models.OrderResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, exists := s.orders[orderID]
+	if !exists {
+		return models.OrderResponse{}, apierror.NotFound("Order not found")
+	}
+	if err := ifMatch.Check(order.Version); err != nil {
+		return models.OrderResponse{}, err
+	}
+
+	order.Shipments = upsertShipment(order.Shipments, update)
+	if order.Status != models.OrderStatusCancelled {
+		order.Status = fulfillmentStatus(order)
+	}
+	order.Touch()
+	s.orders[orderID] = order
+
+	return orderResponse(order), nil
+}
+
+func (s *Service) handleUpdateShipment(c *gin.Context) {
+	var req models.ShipmentUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	order, err := s.UpdateShipment(c.Param("id"), req, etag.IfMatch(c))
+	if err != nil {
+		etag.Respond(c, err)
+		return
+	}
+
+	etag.Set(c, order.Version)
+	c.JSON(http.StatusOK, order)
+}
+
+func upsertShipment(shipments []models.ShipmentUpdate, update models.ShipmentUpdate) []models.ShipmentUpdate {
+	for i, shipment := range shipments {
+		if shipment.ShippingID == update.ShippingID {
+			shipments[i] = update
+			return shipments
+		}
+	}
+	return append(shipments, update)
+}
+
+// fulfillmentStatus derives the order status from how many of the ordered
+// units are in shipments that have been shipped or delivered.
+func fulfillmentStatus(order models.Order) models.OrderStatus {
+	var total int
+	for _, item := range order.Items {
+		total += item.Quantity
+	}
+
+	shipped := coveredUnits(order, func(status models.ShippingStatus) bool {
+		return status.IsShipped()
+	})
+	delivered := coveredUnits(order, func(status models.ShippingStatus) bool {
+		return status == models.ShippingStatusDelivered
+	})
+
+	switch {
+	case total > 0 && delivered >= total:
+		return models.OrderStatusCompleted
+	case delivered > 0:
+		return models.OrderStatusPartiallyDelivered
+	case total > 0 && shipped >= total:
+		return models.OrderStatusShipped
+	case shipped > 0:
+		return models.OrderStatusPartiallyShipped
+	default:
+		return models.OrderStatusPending
+	}
+}
+
+// coveredUnits counts the ordered units contained in shipments whose status
+// matches, never counting more units of a product than were ordered.
+func coveredUnits(order models.Order, match func(models.ShippingStatus) bool) int {
+	ordered := make(map[string]int)
+	for _, item := range order.Items {
+		ordered[item.ProductID] += item.Quantity
+	}
+
+	covered := make(map[string]int)
+	for _, shipment := range order.Shipments {
+		if !match(shipment.Status) {
+			continue
+		}
+		if len(shipment.Items) == 0 {
+			// The shipment holds the whole order.
+			for productID, quantity := range ordered {
+				covered[productID] = quantity
+			}
+			continue
+		}
+		for _, item := range shipment.Items {
+			covered[item.ProductID] += item.Quantity
+		}
+	}
+
+	var units int
+	for productID, quantity := range ordered {
+		if covered[productID] < quantity {
+			quantity = covered[productID]
+		}
+		units += quantity
+	}
+	return units
+}
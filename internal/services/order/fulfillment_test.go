@@ -0,0 +1,93 @@
+package order
+
+import (
+	"testing"
+
+	"saga-order-system/internal/models"
+)
+
+func TestFulfillmentStatus(t *testing.T) {
+	items := []models.OrderItem{
+		{ProductID: "p1", Quantity: 2, Price: 10},
+		{ProductID: "p2", Quantity: 1, Price: 5},
+	}
+	first := []models.ShipmentItem{{ProductID: "p1", Quantity: 2}}
+	second := []models.ShipmentItem{{ProductID: "p2", Quantity: 1}}
+
+	tests := []struct {
+		name      string
+		shipments []models.ShipmentUpdate
+		want      models.OrderStatus
+	}{
+		{
+			name: "nothing shipped",
+			shipments: []models.ShipmentUpdate{
+				{ShippingID: "s1", Status: models.ShippingStatusLabelCreated, Items: first},
+			},
+			want: models.OrderStatusPending,
+		},
+		{
+			name: "one of two parcels picked up",
+			shipments: []models.ShipmentUpdate{
+				{ShippingID: "s1", Status: models.ShippingStatusPickedUp, Items: first},
+				{ShippingID: "s2", Status: models.ShippingStatusLabelCreated, Items: second},
+			},
+			want: models.OrderStatusPartiallyShipped,
+		},
+		{
+			name: "both parcels in transit",
+			shipments: []models.ShipmentUpdate{
+				{ShippingID: "s1", Status: models.ShippingStatusInTransit, Items: first},
+				{ShippingID: "s2", Status: models.ShippingStatusOutForDelivery, Items: second},
+			},
+			want: models.OrderStatusShipped,
+		},
+		{
+			name: "one of two parcels delivered",
+			shipments: []models.ShipmentUpdate{
+				{ShippingID: "s1", Status: models.ShippingStatusDelivered, Items: first},
+				{ShippingID: "s2", Status: models.ShippingStatusInTransit, Items: second},
+			},
+			want: models.OrderStatusPartiallyDelivered,
+		},
+		{
+			name: "both parcels delivered",
+			shipments: []models.ShipmentUpdate{
+				{ShippingID: "s1", Status: models.ShippingStatusDelivered, Items: first},
+				{ShippingID: "s2", Status: models.ShippingStatusDelivered, Items: second},
+			},
+			want: models.OrderStatusCompleted,
+		},
+		{
+			name: "single parcel holding the whole order",
+			shipments: []models.ShipmentUpdate{
+				{ShippingID: "s1", Status: models.ShippingStatusDelivered},
+			},
+			want: models.OrderStatusCompleted,
+		},
+		{
+			name: "extra units do not cover another product",
+			shipments: []models.ShipmentUpdate{
+				{ShippingID: "s1", Status: models.ShippingStatusDelivered, Items: []models.ShipmentItem{{ProductID: "p1", Quantity: 3}}},
+			},
+			want: models.OrderStatusPartiallyDelivered,
+		},
+		{
+			name: "cancelled parcel replaced by a delivered one",
+			shipments: []models.ShipmentUpdate{
+				{ShippingID: "s1", Status: models.ShippingStatusCancelled},
+				{ShippingID: "s2", Status: models.ShippingStatusDelivered},
+			},
+			want: models.OrderStatusCompleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := models.Order{Items: items, Shipments: tt.shipments}
+			if got := fulfillmentStatus(order); got != tt.want {
+				t.Fatalf("fulfillmentStatus() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+var errInsufficientStock = errors.New("insufficient stock")
+
+func (s *Service) handleGetStock(c *gin.Context) {
+	productID := c.Param("product_id")
+
+	s.mu.RLock()
+	stock, exists := s.stock[productID]
+	s.mu.RUnlock()
+
+	if !exists {
+		stock = models.StockLevel{ProductID: productID}
+	}
+
+	c.JSON(http.StatusOK, stock)
+}
+
+// Restock puts returned or newly received units back on hand. Returned
+// units are only put back for products whose stock is tracked. An
+// adjustment whose reference was applied before only reports the levels.
+func (s *Service) Restock(req models.StockAdjustmentRequest) []models.StockLevel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := req.Items
+	if req.Returned {
+		items = s.trackedItems(items)
+	}
+	if req.Reference != "" {
+		if s.restocks[req.Reference] {
+			return s.stockLevels(items)
+		}
+		s.restocks[req.Reference] = true
+	}
+	return s.adjustStock(items, 1)
+}
+
+func (s *Service) handleRestock(c *gin.Context) {
+	var req models.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, s.Restock(req))
+}
+
+// DeductStock takes units off hand, failing without changes when any
+// product does not have enough stock. Like Restock, returned units are only
+// taken off for products whose stock is tracked.
+func (s *Service) DeductStock(req models.StockAdjustmentRequest) ([]models.StockLevel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := req.Items
+	if req.Returned {
+		items = s.trackedItems(items)
+	}
+	for _, item := range items {
+		if s.stock[item.ProductID].Quantity < item.Quantity {
+			return nil, insufficientStock(fmt.Sprintf("Insufficient stock for product %s", item.ProductID))
+		}
+	}
+	return s.adjustStock(items, -1), nil
+}
+
+func (s *Service) handleDeductStock(c *gin.Context) {
+	var req models.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	levels, err := s.DeductStock(req)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, levels)
+}
+
+// trackedItems leaves out the items of products that have never been
+// stocked. Callers must hold s.mu.
+func (s *Service) trackedItems(items []models.ShipmentItem) []models.ShipmentItem {
+	tracked := make([]models.ShipmentItem, 0, len(items))
+	for _, item := range items {
+		if _, exists := s.stock[item.ProductID]; exists {
+			tracked = append(tracked, item)
+		}
+	}
+	return tracked
+}
+
+// stockLevels returns the stock of every item's product. Callers must hold
+// s.mu.
+func (s *Service) stockLevels(items []models.ShipmentItem) []models.StockLevel {
+	levels := make([]models.StockLevel, 0, len(items))
+	for _, item := range items {
+		stock := s.stock[item.ProductID]
+		stock.ProductID = item.ProductID
+		levels = append(levels, stock)
+	}
+	return levels
+}
+
+// adjustStock adds sign*quantity for every item. Callers must hold s.mu.
+func (s *Service) adjustStock(items []models.ShipmentItem, sign int) []models.StockLevel {
+	now := time.Now()
+	levels := make([]models.StockLevel, 0, len(items))
+	for _, item := range items {
+		stock := s.stock[item.ProductID]
+		stock.ProductID = item.ProductID
+		stock.Quantity += sign * item.Quantity
+		stock.UpdatedAt = now
+		s.stock[item.ProductID] = stock
+		levels = append(levels, stock)
+	}
+	return levels
+}
+
+// ReserveStock holds stock for an order. Reserving again for the same order
+// returns the existing reservation, with created false.
+func (s *Service) ReserveStock(req models.ReserveStockRequest) (reservation models.InventoryReservation, created bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if reservation, exists := s.reservations[req.OrderID]; exists {
+		return reservation, false, nil
+	}
+
+	if err := s.reserveStock(req.OrderID, req.Items); err != nil {
+		return models.InventoryReservation{}, false, insufficientStock(err.Error())
+	}
+	return s.reservations[req.OrderID], true, nil
+}
+
+func (s *Service) handleReserveStock(c *gin.Context) {
+	var req models.ReserveStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	reservation, created, err := s.ReserveStock(req)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, reservation)
+}
+
+// ReleaseStock puts an order's reserved stock back on hand and reports
+// whether it had any. Releasing an order without a reservation is not an
+// error.
+func (s *Service) ReleaseStock(orderID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.releaseStock(orderID)
+}
+
+func (s *Service) handleReleaseStock(c *gin.Context) {
+	orderID := c.Param("order_id")
+
+	if !s.ReleaseStock(orderID) {
+		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("No stock reserved for order %s", orderID)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Stock reserved for order %s released", orderID)})
+}
+
+// reserveStock takes items off hand for the order, failing without changes
+// when any product does not have enough stock. Products that have never been
+// stocked are not tracked and are not held. Callers must hold s.mu.
+func (s *Service) reserveStock(orderID string, items []models.ShipmentItem) error {
+	var tracked []models.ShipmentItem
+	for _, item := range items {
+		stock, exists := s.stock[item.ProductID]
+		if !exists {
+			continue
+		}
+		if stock.Quantity < item.Quantity {
+			return fmt.Errorf("%w for product %s", errInsufficientStock, item.ProductID)
+		}
+		tracked = append(tracked, item)
+	}
+
+	s.adjustStock(tracked, -1)
+	s.reservations[orderID] = models.InventoryReservation{
+		OrderID:   orderID,
+		Items:     tracked,
+		CreatedAt: time.Now(),
+	}
+	return nil
+}
+
+func insufficientStock(message string) *apierror.Error {
+	return apierror.New(http.StatusConflict, apierror.CodeInsufficientStock, message)
+}
+
+// releaseStock undoes the order's reservation, if any. Callers must hold
+// s.mu.
+func (s *Service) releaseStock(orderID string) bool {
+	reservation, exists := s.reservations[orderID]
+	if !exists {
+		return false
+	}
+
+	s.adjustStock(reservation.Items, 1)
+	delete(s.reservations, orderID)
+	return true
+}
+
+func stockItems(items []models.OrderItem) []models.ShipmentItem {
+	stockItems := make([]models.ShipmentItem, 0, len(items))
+	for _, item := range items {
+		stockItems = append(stockItems, models.ShipmentItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	return stockItems
+}
@@ -0,0 +1,99 @@
+package order
+
+import (
+	"net/http"
+
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/openapi"
+)
+
+// OpenAPI describes the routes set up by SetupRoutes.
+func OpenAPI() *openapi.Document {
+	doc := openapi.New("Order service", "1.0.0")
+
+	doc.Add(http.MethodPost, "/create-order", openapi.Op{
+		Summary:   "Create an order, locked by the saga creating it if any",
+		Request:   models.CreateOrderRequest{},
+		Responses: map[int]interface{}{http.StatusCreated: models.OrderResponse{}},
+	})
+	doc.Add(http.MethodPost, "/cancel-order", openapi.Op{
+		Summary:   "Cancel an order",
+		Request:   models.CancelOrderRequest{},
+		Responses: map[int]interface{}{http.StatusOK: openapi.Message{}},
+	})
+	doc.Add(http.MethodGet, "/orders/:id", openapi.Op{
+		Summary:   "Get an order",
+		Responses: map[int]interface{}{http.StatusOK: models.OrderResponse{}},
+	})
+	doc.Add(http.MethodPost, "/orders/:id/modify", openapi.Op{
+		Summary:   "Replace the items and/or shipping address of a pending order",
+		Request:   models.ModifyOrderRequest{},
+		Responses: map[int]interface{}{http.StatusOK: models.OrderResponse{}},
+	})
+	doc.Add(http.MethodPost, "/orders/:id/lock", openapi.Op{
+		Summary:   "Take or refresh the lock on an order for a saga",
+		Request:   models.LockOrderRequest{},
+		Responses: map[int]interface{}{http.StatusOK: models.OrderResponse{}},
+	})
+	doc.Add(http.MethodPost, "/orders/:id/unlock", openapi.Op{
+		Summary:   "Release a saga's lock on an order",
+		Request:   models.UnlockOrderRequest{},
+		Responses: map[int]interface{}{http.StatusOK: models.OrderResponse{}},
+	})
+	doc.Add(http.MethodPost, "/orders/:id/shipment-updates", openapi.Op{
+		Summary:   "Record a change in status of one of the order's shipments",
+		Request:   models.ShipmentUpdate{},
+		Responses: map[int]interface{}{http.StatusOK: models.OrderResponse{}},
+	})
+
+	doc.Add(http.MethodPost, "/returns", openapi.Op{
+		Summary:   "Open a return for part of a delivered order",
+		Request:   models.CreateReturnRequest{},
+		Responses: map[int]interface{}{http.StatusCreated: models.Return{}},
+	})
+	doc.Add(http.MethodGet, "/returns/:id", openapi.Op{
+		Summary:   "Get a return",
+		Responses: map[int]interface{}{http.StatusOK: models.Return{}},
+	})
+	doc.Add(http.MethodPost, "/returns/:id/receive", openapi.Op{
+		Summary:   "Mark the goods of a return as received",
+		Responses: map[int]interface{}{http.StatusOK: models.Return{}},
+	})
+	doc.Add(http.MethodPost, "/returns/:id/complete", openapi.Op{
+		Summary:   "Complete a received return",
+		Responses: map[int]interface{}{http.StatusOK: models.Return{}},
+	})
+	doc.Add(http.MethodPost, "/returns/:id/cancel", openapi.Op{
+		Summary:   "Cancel an open return",
+		Responses: map[int]interface{}{http.StatusOK: openapi.Message{}},
+	})
+
+	doc.Add(http.MethodGet, "/inventory/:product_id", openapi.Op{
+		Summary:   "Get the stock level of a product",
+		Responses: map[int]interface{}{http.StatusOK: models.StockLevel{}},
+	})
+	doc.Add(http.MethodPost, "/inventory/restock", openapi.Op{
+		Summary:   "Add stock",
+		Request:   models.StockAdjustmentRequest{},
+		Responses: map[int]interface{}{http.StatusOK: []models.StockLevel{}},
+	})
+	doc.Add(http.MethodPost, "/inventory/deduct", openapi.Op{
+		Summary:   "Take stock off hand",
+		Request:   models.StockAdjustmentRequest{},
+		Responses: map[int]interface{}{http.StatusOK: []models.StockLevel{}},
+	})
+	doc.Add(http.MethodPost, "/inventory/reservations", openapi.Op{
+		Summary: "Hold stock for an order; reserving again returns the existing reservation",
+		Request: models.ReserveStockRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated: models.InventoryReservation{},
+			http.StatusOK:      models.InventoryReservation{},
+		},
+	})
+	doc.Add(http.MethodPost, "/inventory/reservations/:order_id/release", openapi.Op{
+		Summary:   "Release the stock held for an order",
+		Responses: map[int]interface{}{http.StatusOK: openapi.Message{}},
+	})
+
+	return doc
+}
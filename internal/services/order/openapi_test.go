@@ -0,0 +1,9 @@
+package order
+
+import "testing"
+
+func TestOpenAPI(t *testing.T) {
+	if err := OpenAPI().Verify(newTestRouter().Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,170 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+// CreateReturn opens a return for delivered items of an order. Items may
+// not exceed what was ordered minus what is already being returned.
+func (s *Service) CreateReturn(req models.CreateReturnRequest) (models.Return, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, exists := s.orders[req.OrderID]
+	if !exists {
+		return models.Return{}, apierror.NotFound("Order not found")
+	}
+
+	if order.Status != models.OrderStatusCompleted && order.Status != models.OrderStatusPartiallyDelivered {
+		return models.Return{}, apierror.Conflict(fmt.Sprintf("Order %s is %s and has no delivered items to return", req.OrderID, order.Status))
+	}
+
+	returnable := make(map[string]int)
+	for _, item := range order.Items {
+		returnable[item.ProductID] += item.Quantity
+	}
+	for _, ret := range s.returns {
+		if ret.OrderID != req.OrderID || ret.Status == models.ReturnStatusCancelled {
+			continue
+		}
+		for _, item := range ret.Items {
+			returnable[item.ProductID] -= item.Quantity
+		}
+	}
+
+	requested := make(map[string]int)
+	for _, item := range req.Items {
+		requested[item.ProductID] += item.Quantity
+	}
+	for productID, quantity := range requested {
+		if quantity > returnable[productID] {
+			return models.Return{}, apierror.ValidationFailed(fmt.Sprintf("Cannot return %d of product %s", quantity, productID))
+		}
+	}
+
+	ret := models.NewReturn(req, order)
+	s.returns[ret.ID] = ret
+	return ret, nil
+}
+
+func (s *Service) handleCreateReturn(c *gin.Context) {
+	var req models.CreateReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	ret, err := s.CreateReturn(req)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ret)
+}
+
+func (s *Service) handleGetReturn(c *gin.Context) {
+	returnID := c.Param("id")
+
+	s.mu.RLock()
+	ret, exists := s.returns[returnID]
+	s.mu.RUnlock()
+
+	if !exists {
+		apierror.Respond(c, apierror.NotFound("Return not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, ret)
+}
+
+// ReceiveReturn records that the returned items of an open return have
+// arrived.
+func (s *Service) ReceiveReturn(returnID string) (models.Return, error) {
+	return s.transitionReturn(returnID, models.ReturnStatusOpen, models.ReturnStatusReceived)
+}
+
+func (s *Service) handleReceiveReturn(c *gin.Context) {
+	s.respondReturn(c, s.ReceiveReturn)
+}
+
+// CompleteReturn closes a return whose items have been received.
+func (s *Service) CompleteReturn(returnID string) (models.Return, error) {
+	return s.transitionReturn(returnID, models.ReturnStatusReceived, models.ReturnStatusCompleted)
+}
+
+func (s *Service) handleCompleteReturn(c *gin.Context) {
+	s.respondReturn(c, s.CompleteReturn)
+}
+
+// CancelReturn cancels a return that has not been completed.
+func (s *Service) CancelReturn(returnID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ret, exists := s.returns[returnID]
+	if !exists {
+		return apierror.NotFound("Return not found")
+	}
+
+	if ret.Status == models.ReturnStatusCompleted {
+		return apierror.Conflict(fmt.Sprintf("Return %s is already completed", returnID))
+	}
+
+	ret.Status = models.ReturnStatusCancelled
+	ret.UpdatedAt = time.Now()
+	s.returns[returnID] = ret
+	return nil
+}
+
+func (s *Service) handleCancelReturn(c *gin.Context) {
+	returnID := c.Param("id")
+
+	if err := s.CancelReturn(returnID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Return %s cancelled successfully", returnID)})
+}
+
+func (s *Service) transitionReturn(returnID string, from, to models.ReturnStatus) (models.Return, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ret, exists := s.returns[returnID]
+	if !exists {
+		return models.Return{}, apierror.NotFound("Return not found")
+	}
+
+	// A transition retried after a lost response finds it already made.
+	if ret.Status == to {
+		return ret, nil
+	}
+	if ret.Status != from {
+		return models.Return{}, apierror.Conflict(fmt.Sprintf("Return %s is %s, expected %s", returnID, ret.Status, from))
+	}
+
+	ret.Status = to
+	ret.UpdatedAt = time.Now()
+	s.returns[returnID] = ret
+	return ret, nil
+}
+
+// respondReturn responds with the return that transition moved, the one
+// named by the route.
+func (s *Service) respondReturn(c *gin.Context, transition func(returnID string) (models.Return, error)) {
+	ret, err := transition(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ret)
+}
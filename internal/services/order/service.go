@@ -0,0 +1,232 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/etag"
+	"saga-order-system/internal/health"
+	"saga-order-system/internal/models"
+)
+
+type Service struct {
+	orders       map[string]models.Order
+	returns      map[string]models.Return
+	stock        map[string]models.StockLevel
+	reservations map[string]models.InventoryReservation
+	// restocks holds the references of the stock adjustments applied.
+	restocks map[string]bool
+	mu       sync.RWMutex
+}
+
+func NewService() *Service {
+	return &Service{
+		orders:       make(map[string]models.Order),
+		returns:      make(map[string]models.Return),
+		stock:        make(map[string]models.StockLevel),
+		reservations: make(map[string]models.InventoryReservation),
+		restocks:     make(map[string]bool),
+	}
+}
+
+// Ready reports whether the service's store is usable.
+func (s *Service) Ready(ctx context.Context) error {
+	return health.Lockable(ctx, &s.mu)
+}
+
+func (s *Service) SetupRoutes(router *gin.Engine) {
+	router.POST("/create-order", s.handleCreateOrder)
+	router.POST("/cancel-order", s.handleCancelOrder)
+	router.POST("/orders/:id/shipment-updates", s.handleUpdateShipment)
+	router.POST("/returns", s.handleCreateReturn)
+	router.GET("/returns/:id", s.handleGetReturn)
+	router.POST("/returns/:id/receive", s.handleReceiveReturn)
+	router.POST("/returns/:id/complete", s.handleCompleteReturn)
+	router.POST("/returns/:id/cancel", s.handleCancelReturn)
+	router.GET("/inventory/:product_id", s.handleGetStock)
+	router.POST("/inventory/restock", s.handleRestock)
+	router.POST("/inventory/deduct", s.handleDeductStock)
+	router.POST("/inventory/reservations", s.handleReserveStock)
+	router.POST("/inventory/reservations/:order_id/release", s.handleReleaseStock)
+	router.GET("/orders/:id", s.handleGetOrder)
+	router.POST("/orders/:id/modify", s.handleModifyOrder)
+	router.POST("/orders/:id/lock", s.handleLockOrder)
+	router.POST("/orders/:id/unlock", s.handleUnlockOrder)
+}
+
+// CreateOrder creates a pending order. An order created by a saga, named by
+// sagaID, starts out locked by it.
+func (s *Service) CreateOrder(req models.CreateOrderRequest, sagaID string) models.OrderResponse {
+	order := models.NewOrder(req)
+	if sagaID != "" {
+		order.Lock = newOrderLock(sagaID, defaultLockTTL)
+	}
+
+	s.mu.Lock()
+	s.orders[order.ID] = order
+	s.mu.Unlock()
+
+	return orderResponse(order)
+}
+
+func (s *Service) handleCreateOrder(c *gin.Context) {
+	var req models.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	order := s.CreateOrder(req, c.GetHeader(models.SagaIDHeader))
+
+	etag.Set(c, order.Version)
+	c.JSON(http.StatusCreated, order)
+}
+
+// CancelOrder cancels the order and releases its reserved stock. sagaID
+// names the saga cancelling it, which must hold the order's lock if it is
+// locked.
+func (s *Service) CancelOrder(orderID, sagaID string, ifMatch etag.Precondition) (models.OrderResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, err := s.changeableOrder(orderID, sagaID, ifMatch)
+	if err != nil {
+		return models.OrderResponse{}, err
+	}
+
+	order.Status = models.OrderStatusCancelled
+	order.Touch()
+	s.releaseStock(order.ID)
+	s.orders[orderID] = order
+
+	return orderResponse(order), nil
+}
+
+func (s *Service) handleCancelOrder(c *gin.Context) {
+	var req models.CancelOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	order, err := s.CancelOrder(req.OrderID, c.GetHeader(models.SagaIDHeader), etag.IfMatch(c))
+	if err != nil {
+		etag.Respond(c, err)
+		return
+	}
+
+	etag.Set(c, order.Version)
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Order %s cancelled successfully", req.OrderID)})
+}
+
+// ModifyOrder changes the items and/or shipping address of a pending order
+// and recalculates its total.
+func (s *Service) ModifyOrder(orderID string, req models.ModifyOrderRequest, sagaID string, ifMatch etag.Precondition) (models.OrderResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, err := s.changeableOrder(orderID, sagaID, ifMatch)
+	if err != nil {
+		return models.OrderResponse{}, err
+	}
+
+	if order.Status != models.OrderStatusPending {
+		return models.OrderResponse{}, apierror.Conflict(fmt.Sprintf("Order %s is %s and can no longer be modified", orderID, order.Status))
+	}
+
+	if len(req.Items) > 0 {
+		// Move the order's reservation, if any, over to the new items.
+		if reservation, reserved := s.reservations[orderID]; reserved {
+			s.releaseStock(orderID)
+			if err := s.reserveStock(orderID, stockItems(req.Items)); err != nil {
+				s.reserveStock(orderID, reservation.Items)
+				return models.OrderResponse{}, insufficientStock(err.Error())
+			}
+		}
+		order.Items = req.Items
+		order.TotalPrice = models.TotalPrice(req.Items)
+	}
+	if req.ShippingAddress != nil {
+		order.ShippingAddress = *req.ShippingAddress
+	}
+	order.Touch()
+	s.orders[orderID] = order
+
+	return orderResponse(order), nil
+}
+
+func (s *Service) handleModifyOrder(c *gin.Context) {
+	var req models.ModifyOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	order, err := s.ModifyOrder(c.Param("id"), req, c.GetHeader(models.SagaIDHeader), etag.IfMatch(c))
+	if err != nil {
+		etag.Respond(c, err)
+		return
+	}
+
+	etag.Set(c, order.Version)
+	c.JSON(http.StatusOK, order)
+}
+
+func (s *Service) GetOrder(orderID string) (models.OrderResponse, error) {
+	s.mu.RLock()
+	order, exists := s.orders[orderID]
+	s.mu.RUnlock()
+
+	if !exists {
+		return models.OrderResponse{}, apierror.NotFound("Order not found")
+	}
+	return orderResponse(order), nil
+}
+
+func (s *Service) handleGetOrder(c *gin.Context) {
+	order, err := s.GetOrder(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	etag.Set(c, order.Version)
+	c.JSON(http.StatusOK, order)
+}
+
+// changeableOrder returns the order for a change made by the saga named
+// sagaID, or by no saga when it is empty. It fails when the order does not
+// exist, is locked by another saga or does not match ifMatch. Callers must
+// hold s.mu.
+func (s *Service) changeableOrder(orderID, sagaID string, ifMatch etag.Precondition) (models.Order, error) {
+	order, exists := s.orders[orderID]
+	if !exists {
+		return models.Order{}, apierror.NotFound("Order not found")
+	}
+	if order.LockedAgainst(sagaID) {
+		return models.Order{}, lockedError(order)
+	}
+	if err := ifMatch.Check(order.Version); err != nil {
+		return models.Order{}, err
+	}
+	return order, nil
+}
+
+func orderResponse(order models.Order) models.OrderResponse {
+	return models.OrderResponse{
+		ID:              order.ID,
+		UserID:          order.UserID,
+		Items:           order.Items,
+		TotalPrice:      order.TotalPrice,
+		ShippingAddress: order.ShippingAddress,
+		Shipments:       order.Shipments,
+		Status:          order.Status,
+		Lock:            order.Lock,
+		Version:         order.Version,
+		CreatedAt:       order.CreatedAt,
+	}
+}
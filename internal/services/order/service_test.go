@@ -0,0 +1,194 @@
+package order
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/etag"
+	"saga-order-system/internal/models"
+)
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewService().SetupRoutes(router)
+	return router
+}
+
+func request(t *testing.T, router *gin.Engine, method, path string, body interface{}, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func createOrder(t *testing.T, router *gin.Engine, header http.Header) models.OrderResponse {
+	t.Helper()
+	w := request(t, router, http.MethodPost, "/create-order", models.CreateOrderRequest{
+		UserID:          "u1",
+		Items:           []models.OrderItem{{ProductID: "p1", Quantity: 1, Price: 10}},
+		ShippingAddress: models.Address{Recipient: "A", Lines: []string{"1 Main St"}, City: "Springfield", Country: "US"},
+	}, header)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", w.Code, w.Body)
+	}
+	var order models.OrderResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &order); err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		path func(orderID string) string
+		body func(orderID string) interface{}
+	}{
+		{
+			name: "cancel",
+			path: func(string) string { return "/cancel-order" },
+			body: func(id string) interface{} { return models.CancelOrderRequest{OrderID: id} },
+		},
+		{
+			name: "modify",
+			path: func(id string) string { return "/orders/" + id + "/modify" },
+			body: func(string) interface{} {
+				return models.ModifyOrderRequest{Items: []models.OrderItem{{ProductID: "p2", Quantity: 1, Price: 5}}}
+			},
+		},
+		{
+			name: "lock",
+			path: func(id string) string { return "/orders/" + id + "/lock" },
+			body: func(string) interface{} { return models.LockOrderRequest{SagaID: "s1"} },
+		},
+		{
+			name: "unlock",
+			path: func(id string) string { return "/orders/" + id + "/unlock" },
+			body: func(string) interface{} { return models.UnlockOrderRequest{SagaID: "s1"} },
+		},
+		{
+			name: "shipment update",
+			path: func(id string) string { return "/orders/" + id + "/shipment-updates" },
+			body: func(string) interface{} {
+				return models.ShipmentUpdate{ShippingID: "sh1", Status: models.ShippingStatusLabelCreated}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter()
+			order := createOrder(t, router, nil)
+
+			stale := http.Header{"If-Match": {etag.Format(order.Version + 1)}}
+			if w := request(t, router, http.MethodPost, tt.path(order.ID), tt.body(order.ID), stale); w.Code != http.StatusPreconditionFailed {
+				t.Fatalf("stale If-Match: status = %d, want %d: %s", w.Code, http.StatusPreconditionFailed, w.Body)
+			}
+			if w := request(t, router, http.MethodGet, "/orders/"+order.ID, nil, nil); w.Header().Get("ETag") != etag.Format(order.Version) {
+				t.Fatalf("order changed by a refused request: ETag = %s", w.Header().Get("ETag"))
+			}
+
+			current := http.Header{"If-Match": {etag.Format(order.Version)}}
+			if w := request(t, router, http.MethodPost, tt.path(order.ID), tt.body(order.ID), current); w.Code != http.StatusOK {
+				t.Fatalf("current If-Match: status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+		})
+	}
+}
+
+func TestOrderLock(t *testing.T) {
+	router := newTestRouter()
+	order := createOrder(t, router, http.Header{models.SagaIDHeader: {"s1"}})
+	modify := models.ModifyOrderRequest{Items: []models.OrderItem{{ProductID: "p2", Quantity: 1, Price: 5}}}
+
+	w := request(t, router, http.MethodPost, "/orders/"+order.ID+"/modify", modify, nil)
+	if w.Code != http.StatusLocked {
+		t.Fatalf("modify without the saga: status = %d, want %d", w.Code, http.StatusLocked)
+	}
+	if strings.Contains(w.Body.String(), "s1") {
+		t.Fatalf("locked response reveals the lock holder: %s", w.Body)
+	}
+	if w := request(t, router, http.MethodGet, "/orders/"+order.ID, nil, nil); strings.Contains(w.Body.String(), "s1") {
+		t.Fatalf("order reveals the lock holder: %s", w.Body)
+	}
+
+	if w := request(t, router, http.MethodPost, "/orders/"+order.ID+"/lock", models.LockOrderRequest{SagaID: "s2"}, nil); w.Code != http.StatusLocked {
+		t.Fatalf("lock by another saga: status = %d, want %d", w.Code, http.StatusLocked)
+	}
+	if w := request(t, router, http.MethodPost, "/orders/"+order.ID+"/modify", modify, http.Header{models.SagaIDHeader: {"s1"}}); w.Code != http.StatusOK {
+		t.Fatalf("modify by the lock holder: status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	if w := request(t, router, http.MethodPost, "/orders/"+order.ID+"/unlock", models.UnlockOrderRequest{SagaID: "s1"}, nil); w.Code != http.StatusOK {
+		t.Fatalf("unlock: status = %d: %s", w.Code, w.Body)
+	}
+	if w := request(t, router, http.MethodPost, "/orders/"+order.ID+"/modify", modify, nil); w.Code != http.StatusOK {
+		t.Fatalf("modify after unlock: status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	if w := request(t, router, http.MethodPost, "/cancel-order", models.CancelOrderRequest{OrderID: order.ID}, nil); w.Code != http.StatusOK {
+		t.Fatalf("cancel: status = %d: %s", w.Code, w.Body)
+	}
+	if w := request(t, router, http.MethodPost, "/orders/"+order.ID+"/lock", models.LockOrderRequest{SagaID: "s2"}, nil); w.Code != http.StatusConflict {
+		t.Fatalf("lock of a cancelled order: status = %d, want %d", w.Code, http.StatusConflict)
+	}
+}
+
+func TestReturnRetries(t *testing.T) {
+	service := NewService()
+	service.Restock(models.StockAdjustmentRequest{Items: []models.ShipmentItem{{ProductID: "p1", Quantity: 5}}})
+	service.returns["r1"] = models.Return{ID: "r1", Status: models.ReturnStatusReceived}
+
+	// Each call is made twice, the second as a retry after a lost response.
+	returned := models.StockAdjustmentRequest{Items: []models.ShipmentItem{{ProductID: "p1", Quantity: 2}}, Returned: true, Reference: "saga-1"}
+	for i := 0; i < 2; i++ {
+		levels := service.Restock(returned)
+		if len(levels) != 1 || levels[0].Quantity != 7 {
+			t.Errorf("restock %d levels = %+v, want 7 of p1", i, levels)
+		}
+		ret, err := service.CompleteReturn("r1")
+		if err != nil || ret.Status != models.ReturnStatusCompleted {
+			t.Errorf("complete %d = %s, %v", i, ret.Status, err)
+		}
+	}
+
+	if _, err := service.ReceiveReturn("r1"); err == nil {
+		t.Error("received a completed return")
+	}
+}
+
+func TestDeductStock(t *testing.T) {
+	service := NewService()
+	service.Restock(models.StockAdjustmentRequest{Items: []models.ShipmentItem{{ProductID: "p1", Quantity: 2}}})
+
+	tooMany := models.StockAdjustmentRequest{Items: []models.ShipmentItem{{ProductID: "p1", Quantity: 1}, {ProductID: "p2", Quantity: 1}}}
+	if _, err := service.DeductStock(tooMany); err == nil {
+		t.Fatal("deducted stock of an untracked product")
+	}
+	// Returned units of untracked products are skipped, as when restocking.
+	tooMany.Returned = true
+	levels, err := service.DeductStock(tooMany)
+	if err != nil || len(levels) != 1 || levels[0].Quantity != 1 {
+		t.Fatalf("deduct returned = %+v, %v", levels, err)
+	}
+}
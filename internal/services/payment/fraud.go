@@ -0,0 +1,188 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type FraudDecision string
+
+const (
+	FraudDecisionApprove FraudDecision = "APPROVE"
+	FraudDecisionReview  FraudDecision = "REVIEW"
+	FraudDecisionReject  FraudDecision = "REJECT"
+)
+
+// FraudRules holds the thresholds used to score a payment. A payment whose
+// score reaches RejectScore is rejected, one reaching ReviewScore is held
+// for manual review.
+type FraudRules struct {
+	VelocityLimit      int
+	VelocityWindow     time.Duration
+	ReviewAmount       float64
+	RejectAmount       float64
+	AddressMismatchHit int
+	ReviewScore        int
+	RejectScore        int
+}
+
+func DefaultFraudRules() FraudRules {
+	return FraudRules{
+		VelocityLimit:      5,
+		VelocityWindow:     10 * time.Minute,
+		ReviewAmount:       1000,
+		RejectAmount:       10000,
+		AddressMismatchHit: 30,
+		ReviewScore:        40,
+		RejectScore:        80,
+	}
+}
+
+// Blocklist is loaded from a JSON file of the form
+//
+//	{"user_ids": ["..."], "card_numbers": ["..."], "addresses": ["..."]}
+type Blocklist struct {
+	UserIDs     []string `json:"user_ids"`
+	CardNumbers []string `json:"card_numbers"`
+	Addresses   []string `json:"addresses"`
+}
+
+func LoadBlocklist(path string) (*Blocklist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blocklist: %w", err)
+	}
+
+	var blocklist Blocklist
+	if err := json.Unmarshal(data, &blocklist); err != nil {
+		return nil, fmt.Errorf("failed to parse blocklist: %w", err)
+	}
+
+	return &blocklist, nil
+}
+
+type FraudCheck struct {
+	UserID          string
+	Amount          float64
+	CardNumber      string
+	BillingAddress  string
+	ShippingAddress string
+}
+
+type FraudResult struct {
+	Decision FraudDecision `json:"decision"`
+	Score    int           `json:"score"`
+	Reasons  []string      `json:"reasons,omitempty"`
+}
+
+// FraudScreener scores payments against FraudRules and a Blocklist before
+// they are captured.
+type FraudScreener struct {
+	rules   FraudRules
+	mu      sync.Mutex
+	history map[string][]time.Time
+	blocked map[string]bool
+}
+
+func NewFraudScreener(rules FraudRules, blocklist *Blocklist) *FraudScreener {
+	screener := &FraudScreener{
+		rules:   rules,
+		history: make(map[string][]time.Time),
+		blocked: make(map[string]bool),
+	}
+
+	if blocklist != nil {
+		for _, id := range blocklist.UserIDs {
+			screener.blocked["user:"+id] = true
+		}
+		for _, number := range blocklist.CardNumbers {
+			screener.blocked["card:"+normalizeCardNumber(number)] = true
+		}
+		for _, address := range blocklist.Addresses {
+			screener.blocked["address:"+normalizeAddress(address)] = true
+		}
+	}
+
+	return screener
+}
+
+// Screen scores the payment and records it for the user's velocity count.
+func (f *FraudScreener) Screen(check FraudCheck) FraudResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result FraudResult
+	hit := func(score int, reason string) {
+		result.Score += score
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	if f.isBlocked(check) {
+		hit(f.rules.RejectScore, "blocklisted")
+	}
+
+	if check.Amount >= f.rules.RejectAmount {
+		hit(f.rules.RejectScore, fmt.Sprintf("amount %.2f exceeds %.2f", check.Amount, f.rules.RejectAmount))
+	} else if check.Amount >= f.rules.ReviewAmount {
+		hit(f.rules.ReviewScore, fmt.Sprintf("amount %.2f exceeds %.2f", check.Amount, f.rules.ReviewAmount))
+	}
+
+	if check.BillingAddress != "" && check.ShippingAddress != "" &&
+		normalizeAddress(check.BillingAddress) != normalizeAddress(check.ShippingAddress) {
+		hit(f.rules.AddressMismatchHit, "billing and shipping address mismatch")
+	}
+
+	if check.UserID != "" {
+		if attempts := f.recordAttempt(check.UserID); attempts > f.rules.VelocityLimit {
+			hit(f.rules.ReviewScore, fmt.Sprintf("%d payments within %s", attempts, f.rules.VelocityWindow))
+		}
+	}
+
+	switch {
+	case result.Score >= f.rules.RejectScore:
+		result.Decision = FraudDecisionReject
+	case result.Score >= f.rules.ReviewScore:
+		result.Decision = FraudDecisionReview
+	default:
+		result.Decision = FraudDecisionApprove
+	}
+
+	return result
+}
+
+func (f *FraudScreener) isBlocked(check FraudCheck) bool {
+	return f.blocked["user:"+check.UserID] ||
+		(check.CardNumber != "" && f.blocked["card:"+normalizeCardNumber(check.CardNumber)]) ||
+		(check.BillingAddress != "" && f.blocked["address:"+normalizeAddress(check.BillingAddress)]) ||
+		(check.ShippingAddress != "" && f.blocked["address:"+normalizeAddress(check.ShippingAddress)])
+}
+
+// recordAttempt adds a payment attempt for the user and returns how many
+// attempts fall inside the velocity window.
+func (f *FraudScreener) recordAttempt(userID string) int {
+	now := time.Now()
+	cutoff := now.Add(-f.rules.VelocityWindow)
+
+	attempts := f.history[userID][:0]
+	for _, at := range f.history[userID] {
+		if at.After(cutoff) {
+			attempts = append(attempts, at)
+		}
+	}
+	attempts = append(attempts, now)
+	f.history[userID] = attempts
+
+	return len(attempts)
+}
+
+func normalizeCardNumber(number string) string {
+	return strings.ReplaceAll(number, " ", "")
+}
+
+func normalizeAddress(address string) string {
+	return strings.Join(strings.Fields(strings.ToLower(address)), " ")
+}
@@ -0,0 +1,51 @@
+package payment
+
+import "testing"
+
+func TestFraudScreener(t *testing.T) {
+	blocklist := &Blocklist{
+		UserIDs:     []string{"blocked-user"},
+		CardNumbers: []string{"4111 1111 1111 1111"},
+	}
+
+	tests := []struct {
+		name  string
+		check FraudCheck
+		want  FraudDecision
+	}{
+		{"ordinary payment", FraudCheck{UserID: "u1", Amount: 10}, FraudDecisionApprove},
+		{"large amount", FraudCheck{UserID: "u1", Amount: 1000}, FraudDecisionReview},
+		{"very large amount", FraudCheck{UserID: "u1", Amount: 10000}, FraudDecisionReject},
+		{"blocklisted user", FraudCheck{UserID: "blocked-user", Amount: 10}, FraudDecisionReject},
+		{"blocklisted card", FraudCheck{UserID: "u1", Amount: 10, CardNumber: "4111111111111111"}, FraudDecisionReject},
+		{"address mismatch", FraudCheck{UserID: "u1", Amount: 10, BillingAddress: "1 Main St", ShippingAddress: "2 Side St"}, FraudDecisionApprove},
+		{"address mismatch on a large amount", FraudCheck{UserID: "u1", Amount: 5000, BillingAddress: "1 Main St", ShippingAddress: "2 Side St"}, FraudDecisionReview},
+		{"same address written differently", FraudCheck{UserID: "u1", Amount: 10, BillingAddress: "1  Main St", ShippingAddress: "1 main st"}, FraudDecisionApprove},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			screener := NewFraudScreener(DefaultFraudRules(), blocklist)
+			if got := screener.Screen(tt.check); got.Decision != tt.want {
+				t.Fatalf("Screen() = %s (score %d, %v), want %s", got.Decision, got.Score, got.Reasons, tt.want)
+			}
+		})
+	}
+}
+
+func TestFraudScreenerVelocity(t *testing.T) {
+	rules := DefaultFraudRules()
+	screener := NewFraudScreener(rules, nil)
+
+	for i := 0; i < rules.VelocityLimit; i++ {
+		if got := screener.Screen(FraudCheck{UserID: "u1", Amount: 10}); got.Decision != FraudDecisionApprove {
+			t.Fatalf("attempt %d: Screen() = %s, want %s", i+1, got.Decision, FraudDecisionApprove)
+		}
+	}
+	if got := screener.Screen(FraudCheck{UserID: "u1", Amount: 10}); got.Decision != FraudDecisionReview {
+		t.Fatalf("attempt over the limit: Screen() = %s, want %s", got.Decision, FraudDecisionReview)
+	}
+	if got := screener.Screen(FraudCheck{UserID: "u2", Amount: 10}); got.Decision != FraudDecisionApprove {
+		t.Fatalf("other user: Screen() = %s, want %s", got.Decision, FraudDecisionApprove)
+	}
+}
@@ -0,0 +1,193 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCardDeclined           = errors.New("card declined")
+	ErrInsufficientFunds      = errors.New("insufficient funds")
+	ErrGatewayTimeout         = errors.New("payment gateway timeout")
+	ErrAuthenticationRequired = errors.New("3-D Secure authentication required")
+	ErrAuthorizationNotFound  = errors.New("authorization not found")
+)
+
+// Gateway abstracts the payment provider used by the payment service.
+type Gateway interface {
+	Authorize(req AuthorizeRequest) (*Authorization, error)
+	Capture(authorizationID string, amount float64) error
+	Refund(authorizationID string, amount float64) error
+	Void(authorizationID string) error
+}
+
+type AuthorizeRequest struct {
+	OrderID    string
+	Amount     float64
+	CardNumber string
+}
+
+type AuthorizationStatus string
+
+const (
+	AuthorizationStatusAuthorized AuthorizationStatus = "AUTHORIZED"
+	AuthorizationStatusCaptured   AuthorizationStatus = "CAPTURED"
+	AuthorizationStatusRefunded   AuthorizationStatus = "REFUNDED"
+	AuthorizationStatusVoided     AuthorizationStatus = "VOIDED"
+)
+
+type Authorization struct {
+	ID       string
+	OrderID  string
+	Amount   float64
+	Captured float64
+	Refunded float64
+	Status   AuthorizationStatus
+}
+
+// FakeGateway is an in-memory Gateway that simulates provider outcomes
+// based on the card number suffix, so every payment path can be exercised
+// without a real provider.
+type FakeGateway struct {
+	mu             sync.Mutex
+	authorizations map[string]*Authorization
+	outcomes       map[string]error
+	// Delay is applied to every Authorize call before the outcome is decided.
+	Delay time.Duration
+}
+
+// NewFakeGateway returns a FakeGateway preloaded with the default test cards:
+//
+//	...0002 declined, ...9995 insufficient funds,
+//	...0119 timeout,  ...3220 3-D Secure challenge.
+//
+// Any other card number is approved.
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		authorizations: make(map[string]*Authorization),
+		outcomes: map[string]error{
+			"0002": ErrCardDeclined,
+			"9995": ErrInsufficientFunds,
+			"0119": ErrGatewayTimeout,
+			"3220": ErrAuthenticationRequired,
+		},
+	}
+}
+
+// SetOutcome makes cards ending in suffix fail with err. A nil err removes
+// the rule so matching cards are approved again.
+func (g *FakeGateway) SetOutcome(suffix string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err == nil {
+		delete(g.outcomes, suffix)
+		return
+	}
+	g.outcomes[suffix] = err
+}
+
+func (g *FakeGateway) Authorize(req AuthorizeRequest) (*Authorization, error) {
+	if g.Delay > 0 {
+		time.Sleep(g.Delay)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.outcomeFor(req.CardNumber); err != nil {
+		return nil, err
+	}
+
+	auth := &Authorization{
+		ID:      uuid.New().String(),
+		OrderID: req.OrderID,
+		Amount:  req.Amount,
+		Status:  AuthorizationStatusAuthorized,
+	}
+	g.authorizations[auth.ID] = auth
+
+	copied := *auth
+	return &copied, nil
+}
+
+func (g *FakeGateway) Capture(authorizationID string, amount float64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, exists := g.authorizations[authorizationID]
+	if !exists {
+		return ErrAuthorizationNotFound
+	}
+	if auth.Status != AuthorizationStatusAuthorized {
+		return fmt.Errorf("cannot capture authorization in status %s", auth.Status)
+	}
+	if cents(amount) > cents(auth.Amount) {
+		return fmt.Errorf("capture amount %.2f exceeds authorized amount %.2f", amount, auth.Amount)
+	}
+
+	auth.Captured = amount
+	auth.Status = AuthorizationStatusCaptured
+	return nil
+}
+
+func (g *FakeGateway) Refund(authorizationID string, amount float64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, exists := g.authorizations[authorizationID]
+	if !exists {
+		return ErrAuthorizationNotFound
+	}
+	if auth.Status != AuthorizationStatusCaptured {
+		return fmt.Errorf("cannot refund authorization in status %s", auth.Status)
+	}
+	refunded := cents(auth.Refunded) + cents(amount)
+	if refunded > cents(auth.Captured) {
+		return fmt.Errorf("refund amount %.2f exceeds captured amount %.2f", fromCents(refunded), auth.Captured)
+	}
+
+	auth.Refunded = fromCents(refunded)
+	if refunded == cents(auth.Captured) {
+		auth.Status = AuthorizationStatusRefunded
+	}
+	return nil
+}
+
+func (g *FakeGateway) Void(authorizationID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, exists := g.authorizations[authorizationID]
+	if !exists {
+		return ErrAuthorizationNotFound
+	}
+	if auth.Status != AuthorizationStatusAuthorized {
+		return fmt.Errorf("cannot void authorization in status %s", auth.Status)
+	}
+
+	auth.Status = AuthorizationStatusVoided
+	return nil
+}
+
+// outcomeFor returns the simulated error for the longest matching suffix.
+func (g *FakeGateway) outcomeFor(cardNumber string) error {
+	cardNumber = strings.ReplaceAll(cardNumber, " ", "")
+
+	var (
+		matched string
+		outcome error
+	)
+	for suffix, err := range g.outcomes {
+		if strings.HasSuffix(cardNumber, suffix) && len(suffix) > len(matched) {
+			matched = suffix
+			outcome = err
+		}
+	}
+	return outcome
+}
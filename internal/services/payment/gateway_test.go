@@ -0,0 +1,96 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFakeGatewayCardOutcomes(t *testing.T) {
+	tests := []struct {
+		name string
+		card string
+		want error
+	}{
+		{"approved", "4242424242424242", nil},
+		{"declined", "4000000000000002", ErrCardDeclined},
+		{"insufficient funds", "4000000000009995", ErrInsufficientFunds},
+		{"timeout", "4000000000000119", ErrGatewayTimeout},
+		{"3-D Secure challenge", "4000000000003220", ErrAuthenticationRequired},
+		{"spaces ignored", "4000 0000 0000 0002", ErrCardDeclined},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := NewFakeGateway()
+			auth, err := gateway.Authorize(AuthorizeRequest{OrderID: "o1", Amount: 10, CardNumber: tt.card})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Authorize(%s) error = %v, want %v", tt.card, err, tt.want)
+			}
+			if tt.want == nil && auth.Status != AuthorizationStatusAuthorized {
+				t.Fatalf("Authorize(%s) status = %s, want %s", tt.card, auth.Status, AuthorizationStatusAuthorized)
+			}
+		})
+	}
+}
+
+func TestFakeGatewaySetOutcome(t *testing.T) {
+	gateway := NewFakeGateway()
+	gateway.SetOutcome("4242", ErrInsufficientFunds)
+	if _, err := gateway.Authorize(AuthorizeRequest{CardNumber: "4242424242424242"}); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("error = %v, want %v", err, ErrInsufficientFunds)
+	}
+
+	gateway.SetOutcome("4242", nil)
+	if _, err := gateway.Authorize(AuthorizeRequest{CardNumber: "4242424242424242"}); err != nil {
+		t.Fatalf("error = %v after removing the outcome", err)
+	}
+
+	// The longest matching suffix wins.
+	gateway.SetOutcome("00000002", ErrGatewayTimeout)
+	if _, err := gateway.Authorize(AuthorizeRequest{CardNumber: "4000000000000002"}); !errors.Is(err, ErrGatewayTimeout) {
+		t.Fatalf("error = %v, want %v", err, ErrGatewayTimeout)
+	}
+}
+
+func TestFakeGatewayRefund(t *testing.T) {
+	tests := []struct {
+		name       string
+		captured   float64
+		refunds    []float64
+		wantErr    bool
+		wantStatus AuthorizationStatus
+	}{
+		{"full", 10, []float64{10}, false, AuthorizationStatusRefunded},
+		{"partial", 10, []float64{4}, false, AuthorizationStatusCaptured},
+		{"remainder of 10.01", 10.01, []float64{0.71, 9.30}, false, AuthorizationStatusRefunded},
+		{"remainder of 0.23", 0.23, []float64{0.08, 0.15}, false, AuthorizationStatusRefunded},
+		{"in three parts", 0.3, []float64{0.1, 0.1, 0.1}, false, AuthorizationStatusRefunded},
+		{"more than captured", 10, []float64{6, 4.01}, true, AuthorizationStatusCaptured},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := NewFakeGateway()
+			auth, err := gateway.Authorize(AuthorizeRequest{OrderID: "o1", Amount: tt.captured, CardNumber: "4242424242424242"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := gateway.Capture(auth.ID, tt.captured); err != nil {
+				t.Fatal(err)
+			}
+
+			var refundErr error
+			for _, amount := range tt.refunds {
+				if refundErr = gateway.Refund(auth.ID, amount); refundErr != nil {
+					break
+				}
+			}
+			if (refundErr != nil) != tt.wantErr {
+				t.Fatalf("Refund error = %v, want error %v", refundErr, tt.wantErr)
+			}
+			if status := gateway.authorizations[auth.ID].Status; status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s", status, tt.wantStatus)
+			}
+		})
+	}
+}
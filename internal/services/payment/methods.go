@@ -0,0 +1,120 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+var (
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+	ErrBankTransferPending   = errors.New("bank transfer payments are not supported yet")
+)
+
+func (s *Service) handleCreatePaymentMethod(c *gin.Context) {
+	var req models.CreatePaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	method := models.NewPaymentMethod(req)
+
+	s.mu.Lock()
+	if method.Type == models.PaymentMethodCard {
+		cardNumber := strings.ReplaceAll(req.CardNumber, " ", "")
+		method.CardToken = "tok_" + uuid.New().String()
+		if len(cardNumber) >= 4 {
+			method.CardLast4 = cardNumber[len(cardNumber)-4:]
+		}
+		s.cardVault[method.CardToken] = cardNumber
+	}
+	s.methods[method.ID] = method
+	s.mu.Unlock()
+
+	c.JSON(http.StatusCreated, method)
+}
+
+func (s *Service) handleListPaymentMethods(c *gin.Context) {
+	userID := c.Param("id")
+
+	s.mu.RLock()
+	methods := []models.PaymentMethod{}
+	for _, method := range s.methods {
+		if method.UserID == userID {
+			methods = append(methods, method)
+		}
+	}
+	s.mu.RUnlock()
+
+	c.JSON(http.StatusOK, methods)
+}
+
+func (s *Service) handleGetWallet(c *gin.Context) {
+	c.JSON(http.StatusOK, s.wallets.Get(c.Param("user_id")))
+}
+
+func (s *Service) handleTopUpWallet(c *gin.Context) {
+	var req models.TopUpWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	balance, err := s.wallets.Credit(c.Param("user_id"), cents(req.Amount))
+	if err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// resolvePaymentMethod returns the saved method referenced by the request,
+// or an unsaved card method when the request carries a card number instead.
+func (s *Service) resolvePaymentMethod(req models.ProcessPaymentRequest) (models.PaymentMethod, string, error) {
+	if req.PaymentMethodID == "" {
+		return models.PaymentMethod{UserID: req.UserID, Type: models.PaymentMethodCard}, req.CardNumber, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	method, exists := s.methods[req.PaymentMethodID]
+	if !exists || method.UserID != req.UserID {
+		return models.PaymentMethod{}, "", ErrPaymentMethodNotFound
+	}
+
+	return method, s.cardVault[method.CardToken], nil
+}
+
+func (s *Service) debitWallet(payment *models.Payment) error {
+	if _, err := s.wallets.Debit(payment.UserID, cents(payment.Amount)); err != nil {
+		payment.Status = models.PaymentStatusFailed
+		payment.FailureReason = err.Error()
+		return err
+	}
+
+	payment.Status = models.PaymentStatusSuccess
+	return nil
+}
+
+// refund returns amount of a captured payment through the channel it was
+// paid with.
+func (s *Service) refund(payment models.Payment, amount float64) error {
+	switch payment.Method {
+	case models.PaymentMethodWallet:
+		_, err := s.wallets.Credit(payment.UserID, cents(amount))
+		return err
+	case models.PaymentMethodCard:
+		return s.gateway.Refund(payment.GatewayRef, amount)
+	default:
+		return fmt.Errorf("refunds are not supported for %s payments", payment.Method)
+	}
+}
@@ -0,0 +1,13 @@
+package payment
+
+import "math"
+
+// cents converts an amount to whole cents. Amounts are compared and summed
+// in cents, as float64 sums such as 0.71 + 9.30 do not equal 10.01.
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
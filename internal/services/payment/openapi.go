@@ -0,0 +1,74 @@
+package payment
+
+import (
+	"net/http"
+
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/openapi"
+)
+
+// OpenAPI describes the routes set up by SetupRoutes.
+func OpenAPI() *openapi.Document {
+	doc := openapi.New("Payment service", "1.0.0")
+
+	doc.Add(http.MethodPost, "/process-payment", openapi.Op{
+		Summary: "Charge for an order; 202 means the payment is held for fraud review",
+		Request: models.ProcessPaymentRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:  models.PaymentResponse{},
+			http.StatusAccepted: models.PaymentResponse{},
+		},
+	})
+	doc.Add(http.MethodPost, "/refund-payment", openapi.Op{
+		Summary:   "Refund part or all of an order's payments",
+		Request:   models.RefundPaymentRequest{},
+		Responses: map[int]interface{}{http.StatusOK: openapi.Message{}},
+	})
+	doc.Add(http.MethodPost, "/void-payment", openapi.Op{
+		Summary:   "Release an order's payments held for fraud review",
+		Request:   models.VoidPaymentRequest{},
+		Responses: map[int]interface{}{http.StatusOK: openapi.Message{}},
+	})
+	doc.Add(http.MethodGet, "/payments/:id", openapi.Op{
+		Summary:   "Get a payment",
+		Responses: map[int]interface{}{http.StatusOK: models.PaymentResponse{}},
+	})
+	doc.Add(http.MethodGet, "/payment-reviews", openapi.Op{
+		Summary:   "List the payments held for fraud review",
+		Responses: map[int]interface{}{http.StatusOK: []models.Payment{}},
+	})
+	doc.Add(http.MethodPost, "/payments/:id/review", openapi.Op{
+		Summary:   "Approve or reject a payment held for fraud review",
+		Request:   models.ReviewPaymentRequest{},
+		Responses: map[int]interface{}{http.StatusOK: models.PaymentResponse{}},
+	})
+
+	doc.Add(http.MethodPost, "/payment-methods", openapi.Op{
+		Summary:   "Save a payment method for a user",
+		Request:   models.CreatePaymentMethodRequest{},
+		Responses: map[int]interface{}{http.StatusCreated: models.PaymentMethod{}},
+	})
+	doc.Add(http.MethodGet, "/users/:id/payment-methods", openapi.Op{
+		Summary:   "List a user's saved payment methods",
+		Responses: map[int]interface{}{http.StatusOK: []models.PaymentMethod{}},
+	})
+	doc.Add(http.MethodGet, "/wallets/:user_id", openapi.Op{
+		Summary:   "Get a user's wallet",
+		Responses: map[int]interface{}{http.StatusOK: models.Wallet{}},
+	})
+	doc.Add(http.MethodPost, "/wallets/:user_id/top-up", openapi.Op{
+		Summary:   "Add funds to a user's wallet",
+		Request:   models.TopUpWalletRequest{},
+		Responses: map[int]interface{}{http.StatusOK: models.Wallet{}},
+	})
+
+	doc.Add(http.MethodPost, "/set-fail-next-payment", openapi.Op{
+		Summary: "Make the next payment fail, for testing compensation",
+		Request: struct {
+			Fail bool `json:"fail"`
+		}{},
+		Responses: map[int]interface{}{http.StatusOK: openapi.Message{}},
+	})
+
+	return doc
+}
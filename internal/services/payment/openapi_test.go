@@ -0,0 +1,10 @@
+package payment
+
+import "testing"
+
+func TestOpenAPI(t *testing.T) {
+	_, router := newTestRouter()
+	if err := OpenAPI().Verify(router.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,135 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/etag"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/tracing"
+)
+
+var ErrPaymentRejected = errors.New("payment rejected by fraud screening")
+
+func (s *Service) handleListPendingReviews(c *gin.Context) {
+	s.mu.RLock()
+	pending := []models.Payment{}
+	for _, payment := range s.payments {
+		if payment.Status == models.PaymentStatusPendingReview {
+			pending = append(pending, payment)
+		}
+	}
+	s.mu.RUnlock()
+
+	c.JSON(http.StatusOK, pending)
+}
+
+// handleReviewPayment records a manual fraud review decision. Approved
+// payments are captured, rejected ones released, and the saga waiting on the
+// payment is notified through the callback URL given when the payment was
+// made.
+func (s *Service) handleReviewPayment(c *gin.Context) {
+	paymentID := c.Param("id")
+
+	var req models.ReviewPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	// Claim the payment so that concurrent reviews cannot both settle it.
+	s.mu.Lock()
+	payment, exists := s.payments[paymentID]
+	if !exists {
+		s.mu.Unlock()
+		apierror.Respond(c, apierror.NotFound("Payment not found"))
+		return
+	}
+	if payment.Status != models.PaymentStatusPendingReview {
+		s.mu.Unlock()
+		apierror.Respond(c, apierror.Conflict(fmt.Sprintf("Payment is %s, not awaiting review", payment.Status)))
+		return
+	}
+	if err := etag.IfMatch(c).Check(payment.Version); err != nil {
+		s.mu.Unlock()
+		etag.Respond(c, err)
+		return
+	}
+	payment.Status = models.PaymentStatusPending
+	payment.Touch()
+	s.payments[paymentID] = payment
+	s.mu.Unlock()
+
+	approved := req.Approved
+	if approved {
+		if err := s.settle(&payment); err != nil {
+			approved = false
+		}
+	} else {
+		if payment.GatewayRef != "" {
+			s.gateway.Void(payment.GatewayRef)
+		}
+		payment.Status = models.PaymentStatusRejected
+		payment.FailureReason = ErrPaymentRejected.Error()
+	}
+
+	s.savePayment(&payment)
+
+	if payment.CallbackURL != "" {
+		notification := models.PaymentReviewNotification{
+			PaymentID: payment.ID,
+			OrderID:   payment.OrderID,
+			Approved:  approved,
+			Status:    payment.Status,
+			Note:      req.Note,
+		}
+		if err := s.notifyReview(c.Request.Context(), payment.CallbackURL, notification); err != nil {
+			logging.FromContext(c.Request.Context()).Warn("Failed to notify review outcome", "payment_id", payment.ID, "error", err)
+		}
+	}
+
+	etag.Set(c, payment.Version)
+	c.JSON(http.StatusOK, paymentResponse(payment))
+}
+
+// settle takes the money for a payment that was held for review.
+func (s *Service) settle(payment *models.Payment) error {
+	if payment.Method == models.PaymentMethodWallet {
+		return s.debitWallet(payment)
+	}
+	return s.capture(payment)
+}
+
+func (s *Service) notifyReview(ctx context.Context, callbackURL string, notification models.PaymentReviewNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	logging.Propagate(ctx, req.Header)
+	tracing.Propagate(ctx, req.Header)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("review callback returned status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
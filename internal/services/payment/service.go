@@ -0,0 +1,413 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/etag"
+	"saga-order-system/internal/health"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/services/payment/wallet"
+)
+
+type Service struct {
+	payments  map[string]models.Payment
+	methods   map[string]models.PaymentMethod
+	cardVault map[string]string
+	// refunds holds the amount of each refund made with a reference.
+	refunds map[string]float64
+	mu      sync.RWMutex
+	gateway Gateway
+	wallets *wallet.Store
+	fraud   *FraudScreener
+	client  *http.Client
+	// Untuk simulasi kegagalan pembayaran
+	failNextPayment bool
+}
+
+// NewService creates the payment service. httpTimeout bounds the callbacks
+// it makes to the orchestrator.
+func NewService(gateway Gateway, fraud *FraudScreener, httpTimeout time.Duration) *Service {
+	return &Service{
+		payments:  make(map[string]models.Payment),
+		methods:   make(map[string]models.PaymentMethod),
+		cardVault: make(map[string]string),
+		refunds:   make(map[string]float64),
+		gateway:   gateway,
+		wallets:   wallet.NewStore(),
+		fraud:     fraud,
+		client: &http.Client{
+			Timeout: httpTimeout,
+		},
+		failNextPayment: false,
+	}
+}
+
+// Ready reports whether the service's store is usable.
+func (s *Service) Ready(ctx context.Context) error {
+	return health.Lockable(ctx, &s.mu)
+}
+
+func (s *Service) SetupRoutes(router *gin.Engine) {
+	router.POST("/process-payment", s.handleProcessPayment)
+	router.POST("/refund-payment", s.handleRefundPayment)
+	router.POST("/void-payment", s.handleVoidPayment)
+	router.GET("/payments/:id", s.handleGetPayment)
+	router.POST("/set-fail-next-payment", s.handleSetFailNextPayment)
+	router.POST("/payment-methods", s.handleCreatePaymentMethod)
+	router.GET("/users/:id/payment-methods", s.handleListPaymentMethods)
+	router.GET("/wallets/:user_id", s.handleGetWallet)
+	router.POST("/wallets/:user_id/top-up", s.handleTopUpWallet)
+	router.GET("/payment-reviews", s.handleListPendingReviews)
+	router.POST("/payments/:id/review", s.handleReviewPayment)
+}
+
+func (s *Service) handleSetFailNextPayment(c *gin.Context) {
+	var req struct {
+		Fail bool `json:"fail"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	s.failNextPayment = req.Fail
+	s.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Set fail next payment to %v", req.Fail)})
+}
+
+// ProcessPayment charges for an order. Payments the fraud screen flags for
+// review are only authorized and come back PENDING_REVIEW; the outcome is
+// posted to the request's CallbackURL once a reviewer decides.
+func (s *Service) ProcessPayment(req models.ProcessPaymentRequest) (models.PaymentResponse, error) {
+	// Simulasi kegagalan pembayaran
+	s.mu.Lock()
+	failPayment := s.failNextPayment
+	s.failNextPayment = false
+	s.mu.Unlock()
+	if failPayment {
+		return models.PaymentResponse{}, apierror.Internal("Payment processing failed")
+	}
+
+	method, cardNumber, err := s.resolvePaymentMethod(req)
+	if err != nil {
+		return models.PaymentResponse{}, apierror.NotFound(err.Error())
+	}
+
+	payment := models.NewPayment(req)
+	payment.Method = method.Type
+	payment.PaymentMethodID = method.ID
+
+	check := FraudCheck{
+		UserID:     req.UserID,
+		Amount:     req.Amount,
+		CardNumber: cardNumber,
+	}
+	if req.BillingAddress != nil {
+		check.BillingAddress = req.BillingAddress.String()
+	}
+	if req.ShippingAddress != nil {
+		check.ShippingAddress = req.ShippingAddress.String()
+	}
+	result := s.fraud.Screen(check)
+	payment.FraudScore = result.Score
+	payment.FraudReasons = result.Reasons
+
+	if result.Decision == FraudDecisionReject {
+		payment.Status = models.PaymentStatusRejected
+		payment.FailureReason = ErrPaymentRejected.Error()
+		s.savePayment(&payment)
+		rejected := apierror.New(http.StatusForbidden, apierror.CodePaymentRejected, ErrPaymentRejected.Error())
+		return models.PaymentResponse{}, rejected.WithDetail("payment", paymentResponse(payment))
+	}
+
+	// Payments flagged for review are authorized but only captured once a
+	// reviewer approves them.
+	review := result.Decision == FraudDecisionReview
+
+	switch method.Type {
+	case models.PaymentMethodWallet:
+		if !review {
+			err = s.debitWallet(&payment)
+		}
+	case models.PaymentMethodBankTransfer:
+		return models.PaymentResponse{}, apierror.New(http.StatusNotImplemented, apierror.CodeNotImplemented, ErrBankTransferPending.Error())
+	default:
+		if err = s.authorize(&payment, cardNumber); err == nil && !review {
+			err = s.capture(&payment)
+		}
+	}
+	if err != nil {
+		s.savePayment(&payment)
+		return models.PaymentResponse{}, paymentError(err).WithDetail("payment", paymentResponse(payment))
+	}
+
+	if review {
+		payment.Status = models.PaymentStatusPendingReview
+	}
+	s.savePayment(&payment)
+	return paymentResponse(payment), nil
+}
+
+func (s *Service) handleProcessPayment(c *gin.Context) {
+	var req models.ProcessPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	payment, err := s.ProcessPayment(req)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if payment.Status == models.PaymentStatusPendingReview {
+		status = http.StatusAccepted
+	}
+	etag.Set(c, payment.Version)
+	c.JSON(status, payment)
+}
+
+// authorize places a hold on the card through the gateway and records the
+// authorization on payment.
+func (s *Service) authorize(payment *models.Payment, cardNumber string) error {
+	auth, err := s.gateway.Authorize(AuthorizeRequest{
+		OrderID:    payment.OrderID,
+		Amount:     payment.Amount,
+		CardNumber: cardNumber,
+	})
+	if err != nil {
+		payment.Status = models.PaymentStatusFailed
+		if errors.Is(err, ErrAuthenticationRequired) {
+			payment.Status = models.PaymentStatusRequiresAction
+		}
+		payment.FailureReason = err.Error()
+		return err
+	}
+
+	payment.GatewayRef = auth.ID
+	return nil
+}
+
+// capture settles an authorized card payment. An authorization that cannot
+// be captured is voided.
+func (s *Service) capture(payment *models.Payment) error {
+	if err := s.gateway.Capture(payment.GatewayRef, payment.Amount); err != nil {
+		s.gateway.Void(payment.GatewayRef)
+		payment.Status = models.PaymentStatusFailed
+		payment.FailureReason = err.Error()
+		return err
+	}
+
+	payment.Status = models.PaymentStatusSuccess
+	return nil
+}
+
+func (s *Service) savePayment(payment *models.Payment) {
+	payment.Touch()
+
+	s.mu.Lock()
+	s.payments[payment.ID] = *payment
+	s.mu.Unlock()
+}
+
+// RefundPayment refunds amount across the order's captured payments, oldest
+// first, or everything not refunded yet when amount is omitted. An order may
+// have several payments once it has been modified; If-Match must name the
+// version of each of them. It returns the amount refunded. A refund whose
+// reference was made before is not made again.
+func (s *Service) RefundPayment(req models.RefundPaymentRequest, ifMatch etag.Precondition) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if refunded, done := s.refunds[req.Reference]; done && req.Reference != "" {
+		return refunded, nil
+	}
+
+	var (
+		payments   []models.Payment
+		refundable int64
+	)
+	for _, p := range s.payments {
+		if p.OrderID == req.OrderID &&
+			(p.Status == models.PaymentStatusSuccess || p.Status == models.PaymentStatusPartiallyRefunded) {
+			payments = append(payments, p)
+			refundable += cents(p.Amount) - cents(p.RefundedAmount)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].CreatedAt.Before(payments[j].CreatedAt)
+	})
+
+	if len(payments) == 0 {
+		return 0, apierror.NotFound("Payment not found for the given order")
+	}
+	for _, payment := range payments {
+		if err := ifMatch.Check(payment.Version); err != nil {
+			return 0, err
+		}
+	}
+
+	amount := cents(req.Amount)
+	if amount == 0 {
+		amount = refundable
+	}
+	if amount > refundable {
+		return 0, apierror.ValidationFailed(fmt.Sprintf("Refund amount %.2f exceeds refundable amount %.2f", fromCents(amount), fromCents(refundable)))
+	}
+
+	outstanding := amount
+	for _, payment := range payments {
+		if outstanding <= 0 {
+			break
+		}
+
+		portion := cents(payment.Amount) - cents(payment.RefundedAmount)
+		if portion > outstanding {
+			portion = outstanding
+		}
+
+		if err := s.refund(payment, fromCents(portion)); err != nil {
+			return 0, paymentError(err).WithDetail("refunded", fromCents(amount-outstanding))
+		}
+
+		payment.RefundedAmount = fromCents(cents(payment.RefundedAmount) + portion)
+		payment.Status = models.PaymentStatusPartiallyRefunded
+		if cents(payment.RefundedAmount) >= cents(payment.Amount) {
+			payment.Status = models.PaymentStatusRefunded
+		}
+		payment.Touch()
+		s.payments[payment.ID] = payment
+
+		outstanding -= portion
+	}
+
+	if req.Reference != "" {
+		s.refunds[req.Reference] = fromCents(amount)
+	}
+	return fromCents(amount), nil
+}
+
+func (s *Service) handleRefundPayment(c *gin.Context) {
+	var req models.RefundPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	refunded, err := s.RefundPayment(req, etag.IfMatch(c))
+	if err != nil {
+		etag.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Refunded %.2f of payment for order %s successfully", refunded, req.OrderID)})
+}
+
+// VoidPayment releases the order's payments held for fraud review, which
+// were authorized but not captured, and takes them off the review queue. It
+// returns how many it voided.
+func (s *Service) VoidPayment(orderID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	voided := 0
+	for id, payment := range s.payments {
+		if payment.OrderID != orderID || payment.Status != models.PaymentStatusPendingReview {
+			continue
+		}
+		if payment.GatewayRef != "" {
+			s.gateway.Void(payment.GatewayRef)
+		}
+		payment.Status = models.PaymentStatusVoided
+		payment.FailureReason = "voided before review"
+		payment.Touch()
+		s.payments[id] = payment
+		voided++
+	}
+	return voided
+}
+
+func (s *Service) handleVoidPayment(c *gin.Context) {
+	var req models.VoidPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	voided := s.VoidPayment(req.OrderID)
+	if voided == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("No payment held for order %s", req.OrderID)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%d payment(s) held for order %s voided", voided, req.OrderID)})
+}
+
+func (s *Service) GetPayment(paymentID string) (models.PaymentResponse, error) {
+	s.mu.RLock()
+	payment, exists := s.payments[paymentID]
+	s.mu.RUnlock()
+
+	if !exists {
+		return models.PaymentResponse{}, apierror.NotFound("Payment not found")
+	}
+	return paymentResponse(payment), nil
+}
+
+func (s *Service) handleGetPayment(c *gin.Context) {
+	payment, err := s.GetPayment(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	etag.Set(c, payment.Version)
+	c.JSON(http.StatusOK, payment)
+}
+
+func paymentResponse(payment models.Payment) models.PaymentResponse {
+	return models.PaymentResponse{
+		ID:             payment.ID,
+		OrderID:        payment.OrderID,
+		Amount:         payment.Amount,
+		RefundedAmount: payment.RefundedAmount,
+		Method:         payment.Method,
+		Status:         payment.Status,
+		FailureReason:  payment.FailureReason,
+		FraudScore:     payment.FraudScore,
+		Version:        payment.Version,
+		CreatedAt:      payment.CreatedAt,
+	}
+}
+
+// paymentError maps gateway and wallet errors to the error returned to
+// callers.
+func paymentError(err error) *apierror.Error {
+	switch {
+	case errors.Is(err, ErrCardDeclined),
+		errors.Is(err, ErrInsufficientFunds),
+		errors.Is(err, wallet.ErrInsufficientBalance),
+		errors.Is(err, ErrAuthenticationRequired):
+		return apierror.New(http.StatusPaymentRequired, apierror.CodePaymentDeclined, err.Error())
+	case errors.Is(err, ErrGatewayTimeout):
+		// The gateway may have moved the money before timing out, so
+		// charging again is not safe.
+		timeout := apierror.New(http.StatusGatewayTimeout, apierror.CodeUpstreamTimeout, err.Error())
+		timeout.Retryable = false
+		return timeout
+	default:
+		return apierror.New(http.StatusBadGateway, apierror.CodeUpstreamFailed, err.Error())
+	}
+}
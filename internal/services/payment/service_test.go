@@ -0,0 +1,195 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+func newTestRouter() (*Service, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	service := NewService(NewFakeGateway(), NewFraudScreener(DefaultFraudRules(), nil), time.Second)
+	router := gin.New()
+	service.SetupRoutes(router)
+	return service, router
+}
+
+func post(t *testing.T, router *gin.Engine, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestProcessPaymentCardOutcomes(t *testing.T) {
+	tests := []struct {
+		name       string
+		card       string
+		amount     float64
+		wantStatus int
+		wantCode   apierror.Code
+		wantState  models.PaymentStatus
+	}{
+		{"approved", "4242424242424242", 10, http.StatusCreated, "", models.PaymentStatusSuccess},
+		{"declined", "4000000000000002", 10, http.StatusPaymentRequired, apierror.CodePaymentDeclined, models.PaymentStatusFailed},
+		{"insufficient funds", "4000000000009995", 10, http.StatusPaymentRequired, apierror.CodePaymentDeclined, models.PaymentStatusFailed},
+		{"3-D Secure challenge", "4000000000003220", 10, http.StatusPaymentRequired, apierror.CodePaymentDeclined, models.PaymentStatusRequiresAction},
+		{"timeout", "4000000000000119", 10, http.StatusGatewayTimeout, apierror.CodeUpstreamTimeout, models.PaymentStatusFailed},
+		{"held for review", "4242424242424242", 1500, http.StatusAccepted, "", models.PaymentStatusPendingReview},
+		{"rejected by fraud rules", "4242424242424242", 20000, http.StatusForbidden, apierror.CodePaymentRejected, models.PaymentStatusRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, router := newTestRouter()
+			w := post(t, router, "/process-payment", models.ProcessPaymentRequest{
+				OrderID:    "o1",
+				UserID:     "u1",
+				Amount:     tt.amount,
+				CardNumber: tt.card,
+			})
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			var payment models.PaymentResponse
+			if tt.wantCode != "" {
+				var resp apierror.Response
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.Error.Code != tt.wantCode {
+					t.Fatalf("code = %s, want %s", resp.Error.Code, tt.wantCode)
+				}
+				data, _ := json.Marshal(resp.Error.Details["payment"])
+				json.Unmarshal(data, &payment)
+			} else if err := json.Unmarshal(w.Body.Bytes(), &payment); err != nil {
+				t.Fatal(err)
+			}
+			if payment.Status != tt.wantState {
+				t.Fatalf("payment status = %s, want %s", payment.Status, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestRefundPaymentRemainder(t *testing.T) {
+	_, router := newTestRouter()
+	w := post(t, router, "/process-payment", models.ProcessPaymentRequest{
+		OrderID:    "o1",
+		UserID:     "u1",
+		Amount:     10.01,
+		CardNumber: "4242424242424242",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("process status = %d: %s", w.Code, w.Body)
+	}
+
+	if w := post(t, router, "/refund-payment", models.RefundPaymentRequest{OrderID: "o1", Amount: 0.71}); w.Code != http.StatusOK {
+		t.Fatalf("partial refund status = %d: %s", w.Code, w.Body)
+	}
+	// Refunding what is left, as cancelling a modified order does.
+	if w := post(t, router, "/refund-payment", models.RefundPaymentRequest{OrderID: "o1"}); w.Code != http.StatusOK {
+		t.Fatalf("remainder refund status = %d: %s", w.Code, w.Body)
+	}
+	if w := post(t, router, "/refund-payment", models.RefundPaymentRequest{OrderID: "o1"}); w.Code != http.StatusNotFound {
+		t.Fatalf("refund of a refunded payment status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestVoidPayment(t *testing.T) {
+	service, router := newTestRouter()
+	held := post(t, router, "/process-payment", models.ProcessPaymentRequest{OrderID: "o1", UserID: "u1", Amount: 1500, CardNumber: "4242424242424242"})
+	captured := post(t, router, "/process-payment", models.ProcessPaymentRequest{OrderID: "o1", UserID: "u1", Amount: 10, CardNumber: "4242424242424242"})
+	if held.Code != http.StatusAccepted || captured.Code != http.StatusCreated {
+		t.Fatalf("process status = %d, %d", held.Code, captured.Code)
+	}
+	var heldPayment, capturedPayment models.PaymentResponse
+	json.Unmarshal(held.Body.Bytes(), &heldPayment)
+	json.Unmarshal(captured.Body.Bytes(), &capturedPayment)
+
+	if w := post(t, router, "/void-payment", models.VoidPaymentRequest{OrderID: "o1"}); w.Code != http.StatusOK {
+		t.Fatalf("void status = %d: %s", w.Code, w.Body)
+	}
+	if got := service.payments[heldPayment.ID].Status; got != models.PaymentStatusVoided {
+		t.Errorf("held payment %s, want %s", got, models.PaymentStatusVoided)
+	}
+	if got := service.payments[capturedPayment.ID].Status; got != models.PaymentStatusSuccess {
+		t.Errorf("captured payment %s, want %s", got, models.PaymentStatusSuccess)
+	}
+
+	// A voided payment can no longer be approved.
+	if w := post(t, router, "/payments/"+heldPayment.ID+"/review", models.ReviewPaymentRequest{Approved: true}); w.Code != http.StatusConflict {
+		t.Errorf("review status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	if service.VoidPayment("o1") != 0 {
+		t.Error("voided the same payment twice")
+	}
+}
+
+func TestRefundPaymentReference(t *testing.T) {
+	service, router := newTestRouter()
+	w := post(t, router, "/process-payment", models.ProcessPaymentRequest{OrderID: "o1", UserID: "u1", Amount: 10, CardNumber: "4242424242424242"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("process status = %d: %s", w.Code, w.Body)
+	}
+	var payment models.PaymentResponse
+	json.Unmarshal(w.Body.Bytes(), &payment)
+
+	// The second request is a retry of the first after a lost response.
+	for i := 0; i < 2; i++ {
+		req := models.RefundPaymentRequest{OrderID: "o1", Amount: 4, Reference: "saga-1"}
+		if w := post(t, router, "/refund-payment", req); w.Code != http.StatusOK {
+			t.Fatalf("refund %d status = %d: %s", i, w.Code, w.Body)
+		}
+	}
+	if got := service.payments[payment.ID].RefundedAmount; got != 4 {
+		t.Errorf("refunded %.2f, want 4.00", got)
+	}
+}
+
+func TestWalletPaymentInCents(t *testing.T) {
+	service, router := newTestRouter()
+
+	// 0.70 + 0.10 adds up to just under 0.80 in float64.
+	for _, amount := range []float64{0.7, 0.1} {
+		if w := post(t, router, "/wallets/u1/top-up", models.TopUpWalletRequest{Amount: amount}); w.Code != http.StatusOK {
+			t.Fatalf("top-up status = %d: %s", w.Code, w.Body)
+		}
+	}
+
+	w := post(t, router, "/payment-methods", models.CreatePaymentMethodRequest{UserID: "u1", Type: models.PaymentMethodWallet})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create method status = %d: %s", w.Code, w.Body)
+	}
+	var method models.PaymentMethod
+	json.Unmarshal(w.Body.Bytes(), &method)
+
+	w = post(t, router, "/process-payment", models.ProcessPaymentRequest{OrderID: "o1", UserID: "u1", Amount: 0.8, PaymentMethodID: method.ID})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("process status = %d: %s", w.Code, w.Body)
+	}
+	if got := service.wallets.Get("u1").Balance; got != 0 {
+		t.Errorf("balance %v, want 0", got)
+	}
+
+	if w := post(t, router, "/refund-payment", models.RefundPaymentRequest{OrderID: "o1", Amount: 0.3}); w.Code != http.StatusOK {
+		t.Fatalf("refund status = %d: %s", w.Code, w.Body)
+	}
+	if got := service.wallets.Get("u1").Balance; got != 0.3 {
+		t.Errorf("balance after refund %v, want 0.3", got)
+	}
+}
@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"saga-order-system/internal/models"
+)
+
+var ErrInsufficientBalance = errors.New("insufficient wallet balance")
+
+// Store keeps customer wallet balances in memory. Wallets are created on
+// first credit and an unknown user has a zero balance. Balances, debits and
+// credits are whole cents, so that repeated top-ups and charges never drift
+// the way float64 sums do.
+type Store struct {
+	accounts map[string]account
+	mu       sync.RWMutex
+}
+
+type account struct {
+	balance   int64
+	updatedAt time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		accounts: make(map[string]account),
+	}
+}
+
+func (s *Store) Get(userID string) models.Wallet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.accounts[userID].wallet(userID)
+}
+
+// Debit takes amount cents from the user's wallet, failing without side
+// effects when the balance does not cover it.
+func (s *Store) Debit(userID string, amount int64) (models.Wallet, error) {
+	if amount <= 0 {
+		return models.Wallet{}, fmt.Errorf("debit amount must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc := s.accounts[userID]
+	if acc.balance < amount {
+		return acc.wallet(userID), ErrInsufficientBalance
+	}
+
+	acc.balance -= amount
+	acc.updatedAt = time.Now()
+	s.accounts[userID] = acc
+	return acc.wallet(userID), nil
+}
+
+// Credit adds amount cents to the user's wallet.
+func (s *Store) Credit(userID string, amount int64) (models.Wallet, error) {
+	if amount <= 0 {
+		return models.Wallet{}, fmt.Errorf("credit amount must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc := s.accounts[userID]
+	acc.balance += amount
+	acc.updatedAt = time.Now()
+	s.accounts[userID] = acc
+	return acc.wallet(userID), nil
+}
+
+func (a account) wallet(userID string) models.Wallet {
+	return models.Wallet{
+		UserID:    userID,
+		Balance:   float64(a.balance) / 100,
+		UpdatedAt: a.updatedAt,
+	}
+}
@@ -0,0 +1,190 @@
+package shipping
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+// AddressValidationError lists the invalid fields of an address.
+type AddressValidationError struct {
+	Fields map[string]string `json:"fields"`
+}
+
+func (e *AddressValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field, message := range e.Fields {
+		fields = append(fields, fmt.Sprintf("%s %s", field, message))
+	}
+	sort.Strings(fields)
+	return "invalid address: " + strings.Join(fields, "; ")
+}
+
+type countryRules struct {
+	postalCode     *regexp.Regexp
+	regionRequired bool
+	// formatPostalCode rewrites a postal code into its canonical form
+	// after it has been upper-cased.
+	formatPostalCode func(string) string
+}
+
+var countries = map[string]countryRules{
+	"ID": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"SG": {postalCode: regexp.MustCompile(`^\d{6}$`)},
+	"MY": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"DE": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"US": {
+		postalCode:     regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+		regionRequired: true,
+	},
+	"CA": {
+		postalCode:     regexp.MustCompile(`^[A-Z]\d[A-Z] \d[A-Z]\d$`),
+		regionRequired: true,
+		formatPostalCode: func(code string) string {
+			code = strings.ReplaceAll(code, " ", "")
+			if len(code) == 6 {
+				return code[:3] + " " + code[3:]
+			}
+			return code
+		},
+	},
+	"GB": {
+		postalCode: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? \d[A-Z]{2}$`),
+		formatPostalCode: func(code string) string {
+			code = strings.ReplaceAll(code, " ", "")
+			if len(code) > 3 {
+				return code[:len(code)-3] + " " + code[len(code)-3:]
+			}
+			return code
+		},
+	},
+	"JP": {
+		postalCode: regexp.MustCompile(`^\d{3}-\d{4}$`),
+		formatPostalCode: func(code string) string {
+			if len(code) == 7 && !strings.Contains(code, "-") {
+				return code[:3] + "-" + code[3:]
+			}
+			return code
+		},
+	},
+}
+
+var countryAliases = map[string]string{
+	"INDONESIA":      "ID",
+	"SINGAPORE":      "SG",
+	"MALAYSIA":       "MY",
+	"GERMANY":        "DE",
+	"USA":            "US",
+	"UNITED STATES":  "US",
+	"CANADA":         "CA",
+	"UK":             "GB",
+	"UNITED KINGDOM": "GB",
+	"JAPAN":          "JP",
+}
+
+var phonePattern = regexp.MustCompile(`^\+?\d{6,15}$`)
+
+// NormalizeAddress cleans up an address and validates it against the rules
+// of its country.
+func NormalizeAddress(address models.Address) (models.Address, error) {
+	normalized := models.Address{
+		Recipient:  collapseSpaces(address.Recipient),
+		City:       collapseSpaces(address.City),
+		Region:     collapseSpaces(address.Region),
+		PostalCode: strings.ToUpper(collapseSpaces(address.PostalCode)),
+		Country:    strings.ToUpper(collapseSpaces(address.Country)),
+		Phone:      strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(address.Phone),
+	}
+	for _, line := range address.Lines {
+		if line = collapseSpaces(line); line != "" {
+			normalized.Lines = append(normalized.Lines, line)
+		}
+	}
+	if alias, ok := countryAliases[normalized.Country]; ok {
+		normalized.Country = alias
+	}
+
+	fields := make(map[string]string)
+	if normalized.Recipient == "" {
+		fields["recipient"] = "is required"
+	}
+	if len(normalized.Lines) == 0 {
+		fields["lines"] = "is required"
+	}
+	if normalized.City == "" {
+		fields["city"] = "is required"
+	}
+	if normalized.Phone != "" && !phonePattern.MatchString(normalized.Phone) {
+		fields["phone"] = "is not a valid phone number"
+	}
+
+	rules, supported := countries[normalized.Country]
+	switch {
+	case normalized.Country == "":
+		fields["country"] = "is required"
+	case !supported:
+		fields["country"] = fmt.Sprintf("%s is not supported", normalized.Country)
+	default:
+		if rules.regionRequired && normalized.Region == "" {
+			fields["region"] = "is required"
+		}
+		if normalized.Country == "US" {
+			normalized.Region = strings.ToUpper(normalized.Region)
+		}
+		if rules.formatPostalCode != nil {
+			normalized.PostalCode = rules.formatPostalCode(normalized.PostalCode)
+		}
+		if !rules.postalCode.MatchString(normalized.PostalCode) {
+			fields["postal_code"] = fmt.Sprintf("is not valid for %s", normalized.Country)
+		}
+	}
+
+	if len(fields) > 0 {
+		return normalized, &AddressValidationError{Fields: fields}
+	}
+	return normalized, nil
+}
+
+// ValidateAddress returns the address in its normalized form, or a 422
+// error naming the invalid fields.
+func (s *Service) ValidateAddress(address models.Address) (models.Address, error) {
+	normalized, err := NormalizeAddress(address)
+	if err != nil {
+		return models.Address{}, invalidAddress(err)
+	}
+	return normalized, nil
+}
+
+func (s *Service) handleValidateAddress(c *gin.Context) {
+	var req models.Address
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	address, err := s.ValidateAddress(req)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+func invalidAddress(err error) *apierror.Error {
+	var fields map[string]string
+	if validationErr, ok := err.(*AddressValidationError); ok {
+		fields = validationErr.Fields
+	}
+	return apierror.ValidationFailed(err.Error()).WithDetail("fields", fields)
+}
+
+func collapseSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,103 @@
+package shipping
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"saga-order-system/internal/models"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name       string
+		address    models.Address
+		want       models.Address
+		wantFields []string
+	}{
+		{
+			name: "spaces collapsed and country alias resolved",
+			address: models.Address{
+				Recipient:  "  Jane   Doe ",
+				Lines:      []string{" 1  Main St ", "   "},
+				City:       "Springfield",
+				Region:     "il",
+				PostalCode: "62701",
+				Country:    "united states",
+				Phone:      "+1 (555) 010-9999",
+			},
+			want: models.Address{
+				Recipient:  "Jane Doe",
+				Lines:      []string{"1 Main St"},
+				City:       "Springfield",
+				Region:     "IL",
+				PostalCode: "62701",
+				Country:    "US",
+				Phone:      "+15550109999",
+			},
+		},
+		{
+			name:    "canadian postal code spaced",
+			address: models.Address{Recipient: "A", Lines: []string{"1 Rue"}, City: "Montreal", Region: "QC", PostalCode: "h2x1y4", Country: "CA"},
+			want:    models.Address{Recipient: "A", Lines: []string{"1 Rue"}, City: "Montreal", Region: "QC", PostalCode: "H2X 1Y4", Country: "CA"},
+		},
+		{
+			name:    "british postal code spaced",
+			address: models.Address{Recipient: "A", Lines: []string{"10 Downing St"}, City: "London", PostalCode: "sw1a2aa", Country: "UK"},
+			want:    models.Address{Recipient: "A", Lines: []string{"10 Downing St"}, City: "London", PostalCode: "SW1A 2AA", Country: "GB"},
+		},
+		{
+			name:    "japanese postal code hyphenated",
+			address: models.Address{Recipient: "A", Lines: []string{"1-1 Chiyoda"}, City: "Tokyo", PostalCode: "1000001", Country: "JP"},
+			want:    models.Address{Recipient: "A", Lines: []string{"1-1 Chiyoda"}, City: "Tokyo", PostalCode: "100-0001", Country: "JP"},
+		},
+		{
+			name:       "required fields missing",
+			address:    models.Address{Lines: []string{" "}},
+			wantFields: []string{"recipient", "lines", "city", "country"},
+		},
+		{
+			name:       "region required and postal code checked",
+			address:    models.Address{Recipient: "A", Lines: []string{"1 Main St"}, City: "Springfield", PostalCode: "6270", Country: "US"},
+			wantFields: []string{"region", "postal_code"},
+		},
+		{
+			name:       "unsupported country",
+			address:    models.Address{Recipient: "A", Lines: []string{"1 Rue"}, City: "Paris", PostalCode: "75001", Country: "FR"},
+			wantFields: []string{"country"},
+		},
+		{
+			name:       "invalid phone",
+			address:    models.Address{Recipient: "A", Lines: []string{"1 Jalan"}, City: "Jakarta", PostalCode: "10110", Country: "ID", Phone: "call me"},
+			wantFields: []string{"phone"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeAddress(tt.address)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("NormalizeAddress() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("NormalizeAddress() = %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+
+			var validationErr *AddressValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("NormalizeAddress() error = %v, want an AddressValidationError", err)
+			}
+			if len(validationErr.Fields) != len(tt.wantFields) {
+				t.Errorf("invalid fields %v, want %v", validationErr.Fields, tt.wantFields)
+			}
+			for _, field := range tt.wantFields {
+				if _, ok := validationErr.Fields[field]; !ok {
+					t.Errorf("field %s not reported in %v", field, validationErr.Fields)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package shipping
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"saga-order-system/internal/models"
+)
+
+var (
+	ErrCarrierNotFound = errors.New("carrier not found")
+	ErrNoRates         = errors.New("no shipping rates available")
+	ErrLabelNotFound   = errors.New("label not found")
+)
+
+// Carrier abstracts a shipping provider.
+type Carrier interface {
+	Code() string
+	QuoteRates(req models.ShippingQuoteRequest) ([]models.ShippingRate, error)
+	CreateLabel(req LabelRequest) (*Label, error)
+	CancelLabel(trackingNumber string) error
+	Track(trackingNumber string) ([]models.TrackingEvent, error)
+}
+
+type LabelRequest struct {
+	OrderID  string
+	Address  models.Address
+	WeightKg float64
+	Service  string
+}
+
+type Label struct {
+	Carrier        string
+	Service        string
+	TrackingNumber string
+	Cost           float64
+	CreatedAt      time.Time
+}
+
+// FakeService is one service level offered by a FakeCarrier. Its price is
+// BasePrice plus PerKg for every kilogram of parcel weight.
+type FakeService struct {
+	Name          string
+	BasePrice     float64
+	PerKg         float64
+	EstimatedDays int
+}
+
+// Surcharges a FakeCarrier adds when the destination country differs from
+// its origin country.
+const (
+	internationalSurcharge = 20.0
+	internationalExtraDays = 4
+)
+
+// FakeCarrier is a deterministic in-memory Carrier. Tracking numbers are
+// the carrier prefix followed by a sequence number.
+type FakeCarrier struct {
+	code     string
+	prefix   string
+	origin   string
+	services []FakeService
+	mu       sync.Mutex
+	sequence int
+	labels   map[string]*fakeLabel
+}
+
+type fakeLabel struct {
+	label       Label
+	cancelledAt time.Time
+}
+
+func NewFakeCarrier(code, prefix, origin string, services ...FakeService) *FakeCarrier {
+	return &FakeCarrier{
+		code:     code,
+		prefix:   prefix,
+		origin:   origin,
+		services: services,
+		labels:   make(map[string]*fakeLabel),
+	}
+}
+
+// DefaultCarriers returns the fake carriers used when no real carrier is
+// configured: a fast but expensive one and a cheap but slow one.
+func DefaultCarriers() []Carrier {
+	return []Carrier{
+		NewFakeCarrier("SWIFT", "SWX", "ID",
+			FakeService{Name: "EXPRESS", BasePrice: 25, PerKg: 4, EstimatedDays: 1},
+			FakeService{Name: "STANDARD", BasePrice: 15, PerKg: 3, EstimatedDays: 3},
+		),
+		NewFakeCarrier("ECONO", "ECO", "ID",
+			FakeService{Name: "ECONOMY", BasePrice: 8, PerKg: 1.5, EstimatedDays: 6},
+		),
+	}
+}
+
+func (f *FakeCarrier) Code() string {
+	return f.code
+}
+
+func (f *FakeCarrier) QuoteRates(req models.ShippingQuoteRequest) ([]models.ShippingRate, error) {
+	rates := make([]models.ShippingRate, 0, len(f.services))
+	for _, service := range f.services {
+		rates = append(rates, f.rate(service, req.Address, req.WeightKg))
+	}
+	return rates, nil
+}
+
+func (f *FakeCarrier) rate(service FakeService, address models.Address, weightKg float64) models.ShippingRate {
+	rate := models.ShippingRate{
+		Carrier:       f.code,
+		Service:       service.Name,
+		Price:         service.BasePrice + service.PerKg*weightKg,
+		EstimatedDays: service.EstimatedDays,
+	}
+	if address.Country != f.origin {
+		rate.Price += internationalSurcharge
+		rate.EstimatedDays += internationalExtraDays
+	}
+	return rate
+}
+
+func (f *FakeCarrier) CreateLabel(req LabelRequest) (*Label, error) {
+	service, ok := f.service(req.Service)
+	if !ok {
+		return nil, fmt.Errorf("carrier %s does not offer service %s", f.code, req.Service)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sequence++
+	label := Label{
+		Carrier:        f.code,
+		Service:        service.Name,
+		TrackingNumber: fmt.Sprintf("%s%010d", f.prefix, f.sequence),
+		Cost:           f.rate(service, req.Address, req.WeightKg).Price,
+		CreatedAt:      time.Now(),
+	}
+	f.labels[label.TrackingNumber] = &fakeLabel{label: label}
+
+	return &label, nil
+}
+
+func (f *FakeCarrier) CancelLabel(trackingNumber string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	label, exists := f.labels[trackingNumber]
+	if !exists {
+		return ErrLabelNotFound
+	}
+
+	label.cancelledAt = time.Now()
+	return nil
+}
+
+func (f *FakeCarrier) Track(trackingNumber string) ([]models.TrackingEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	label, exists := f.labels[trackingNumber]
+	if !exists {
+		return nil, ErrLabelNotFound
+	}
+
+	events := []models.TrackingEvent{{
+		Status:      models.ShippingStatusLabelCreated,
+		Description: fmt.Sprintf("%s label created", f.code),
+		OccurredAt:  label.label.CreatedAt,
+	}}
+	if !label.cancelledAt.IsZero() {
+		events = append(events, models.TrackingEvent{
+			Status:      models.ShippingStatusCancelled,
+			Description: fmt.Sprintf("%s label cancelled", f.code),
+			OccurredAt:  label.cancelledAt,
+		})
+	}
+	return events, nil
+}
+
+func (f *FakeCarrier) service(name string) (FakeService, bool) {
+	for _, service := range f.services {
+		if strings.EqualFold(service.Name, name) {
+			return service, true
+		}
+	}
+	return FakeService{}, false
+}
+
+// selectRate picks a rate according to the selection strategy, breaking
+// ties on the other dimension so the choice is deterministic.
+func selectRate(rates []models.ShippingRate, selection models.CarrierSelection) (models.ShippingRate, error) {
+	if len(rates) == 0 {
+		return models.ShippingRate{}, ErrNoRates
+	}
+
+	sorted := append([]models.ShippingRate(nil), rates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if selection == models.CarrierSelectionFastest {
+			if a.EstimatedDays != b.EstimatedDays {
+				return a.EstimatedDays < b.EstimatedDays
+			}
+			return a.Price < b.Price
+		}
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.EstimatedDays < b.EstimatedDays
+	})
+
+	return sorted[0], nil
+}
@@ -0,0 +1,139 @@
+package shipping
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"saga-order-system/internal/models"
+)
+
+func TestSelectRate(t *testing.T) {
+	rates := []models.ShippingRate{
+		{Carrier: "A", Service: "EXPRESS", Price: 30, EstimatedDays: 1},
+		{Carrier: "B", Service: "EXPRESS", Price: 25, EstimatedDays: 1},
+		{Carrier: "A", Service: "ECONOMY", Price: 10, EstimatedDays: 6},
+		{Carrier: "B", Service: "ECONOMY", Price: 10, EstimatedDays: 5},
+	}
+
+	tests := []struct {
+		name      string
+		rates     []models.ShippingRate
+		selection models.CarrierSelection
+		want      models.ShippingRate
+		wantErr   error
+	}{
+		{
+			name:      "cheapest breaks ties on days",
+			rates:     rates,
+			selection: models.CarrierSelectionCheapest,
+			want:      rates[3],
+		},
+		{
+			name:  "cheapest by default",
+			rates: rates,
+			want:  rates[3],
+		},
+		{
+			name:      "fastest breaks ties on price",
+			rates:     rates,
+			selection: models.CarrierSelectionFastest,
+			want:      rates[1],
+		},
+		{
+			name:      "no rates",
+			selection: models.CarrierSelectionFastest,
+			wantErr:   ErrNoRates,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectRate(tt.rates, tt.selection)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("selectRate() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("selectRate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeCarrierQuoteRates(t *testing.T) {
+	carrier := NewFakeCarrier("SWIFT", "SWX", "ID",
+		FakeService{Name: "EXPRESS", BasePrice: 25, PerKg: 4, EstimatedDays: 1},
+	)
+
+	tests := []struct {
+		name    string
+		country string
+		want    models.ShippingRate
+	}{
+		{
+			name:    "domestic",
+			country: "ID",
+			want:    models.ShippingRate{Carrier: "SWIFT", Service: "EXPRESS", Price: 33, EstimatedDays: 1},
+		},
+		{
+			name:    "international surcharge",
+			country: "SG",
+			want:    models.ShippingRate{Carrier: "SWIFT", Service: "EXPRESS", Price: 53, EstimatedDays: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := carrier.QuoteRates(models.ShippingQuoteRequest{
+				Address:  models.Address{Country: tt.country},
+				WeightKg: 2,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []models.ShippingRate{tt.want}; !reflect.DeepEqual(rates, want) {
+				t.Fatalf("QuoteRates() = %+v, want %+v", rates, want)
+			}
+		})
+	}
+}
+
+func TestFakeCarrierLabels(t *testing.T) {
+	carrier := NewFakeCarrier("ECONO", "ECO", "ID",
+		FakeService{Name: "ECONOMY", BasePrice: 8, PerKg: 1.5, EstimatedDays: 6},
+	)
+
+	if _, err := carrier.CreateLabel(LabelRequest{Service: "EXPRESS"}); err == nil {
+		t.Fatal("label created for a service the carrier does not offer")
+	}
+
+	label, err := carrier.CreateLabel(LabelRequest{Address: models.Address{Country: "ID"}, WeightKg: 2, Service: "economy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label.TrackingNumber != "ECO0000000001" || label.Service != "ECONOMY" || label.Cost != 11 {
+		t.Fatalf("label = %+v", label)
+	}
+
+	if err := carrier.CancelLabel(label.TrackingNumber); err != nil {
+		t.Fatal(err)
+	}
+	events, err := carrier.Track(label.TrackingNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []models.ShippingStatus
+	for _, event := range events {
+		statuses = append(statuses, event.Status)
+	}
+	if want := []models.ShippingStatus{models.ShippingStatusLabelCreated, models.ShippingStatusCancelled}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("tracked statuses %v, want %v", statuses, want)
+	}
+
+	if _, err := carrier.Track("ECO9999999999"); !errors.Is(err, ErrLabelNotFound) {
+		t.Errorf("Track() error = %v, want %v", err, ErrLabelNotFound)
+	}
+	if err := carrier.CancelLabel("ECO9999999999"); !errors.Is(err, ErrLabelNotFound) {
+		t.Errorf("CancelLabel() error = %v, want %v", err, ErrLabelNotFound)
+	}
+}
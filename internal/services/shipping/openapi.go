@@ -0,0 +1,71 @@
+package shipping
+
+import (
+	"net/http"
+
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/openapi"
+)
+
+type quotesResponse struct {
+	Rates []models.ShippingRate `json:"rates"`
+}
+
+type carrierTrackingResponse struct {
+	Carrier        string                 `json:"carrier"`
+	TrackingNumber string                 `json:"tracking_number"`
+	Events         []models.TrackingEvent `json:"events"`
+}
+
+// OpenAPI describes the routes set up by SetupRoutes.
+func OpenAPI() *openapi.Document {
+	doc := openapi.New("Shipping service", "1.0.0")
+
+	doc.Add(http.MethodPost, "/addresses/validate", openapi.Op{
+		Summary:   "Validate an address and return it normalized",
+		Request:   models.Address{},
+		Responses: map[int]interface{}{http.StatusOK: models.Address{}},
+	})
+	doc.Add(http.MethodPost, "/shipping-quotes", openapi.Op{
+		Summary:   "Quote rates from one or every carrier",
+		Request:   models.ShippingQuoteRequest{},
+		Responses: map[int]interface{}{http.StatusOK: quotesResponse{}},
+	})
+	doc.Add(http.MethodPost, "/start-shipping", openapi.Op{
+		Summary:   "Book a shipment with a carrier",
+		Request:   models.StartShippingRequest{},
+		Responses: map[int]interface{}{http.StatusCreated: models.ShippingResponse{}},
+	})
+	doc.Add(http.MethodPost, "/cancel-shipping", openapi.Op{
+		Summary:   "Cancel one or all outbound shipments of an order; 409 once picked up",
+		Request:   models.CancelShippingRequest{},
+		Responses: map[int]interface{}{http.StatusOK: openapi.Message{}},
+	})
+	doc.Add(http.MethodGet, "/shippings/:id", openapi.Op{
+		Summary:   "Get a shipment",
+		Responses: map[int]interface{}{http.StatusOK: models.ShippingResponse{}},
+	})
+	doc.Add(http.MethodGet, "/orders/:id/shippings", openapi.Op{
+		Summary:   "List an order's shipments",
+		Responses: map[int]interface{}{http.StatusOK: []models.ShippingResponse{}},
+	})
+	doc.Add(http.MethodPost, "/shippings/:id/tracking-events", openapi.Op{
+		Summary:   "Record a carrier scan, moving the shipment to its status",
+		Request:   models.TrackingEventRequest{},
+		Responses: map[int]interface{}{http.StatusOK: models.ShippingResponse{}},
+	})
+	doc.Add(http.MethodGet, "/shippings/:id/carrier-tracking", openapi.Op{
+		Summary:   "Get the carrier's tracking events for a shipment",
+		Responses: map[int]interface{}{http.StatusOK: carrierTrackingResponse{}},
+	})
+
+	doc.Add(http.MethodPost, "/set-fail-next-shipping", openapi.Op{
+		Summary: "Make the next shipment fail, for testing compensation",
+		Request: struct {
+			Fail bool `json:"fail"`
+		}{},
+		Responses: map[int]interface{}{http.StatusOK: openapi.Message{}},
+	})
+
+	return doc
+}
@@ -0,0 +1,10 @@
+package shipping
+
+import "testing"
+
+func TestOpenAPI(t *testing.T) {
+	router, _, _ := newTestRouter(t)
+	if err := OpenAPI().Verify(router.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
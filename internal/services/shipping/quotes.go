@@ -0,0 +1,152 @@
+package shipping
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/models"
+)
+
+// defaultWeightKg is used for parcels whose weight is not given.
+const defaultWeightKg = 1.0
+
+func (s *Service) handleQuoteShipping(c *gin.Context) {
+	var req models.ShippingQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	if req.WeightKg == 0 {
+		req.WeightKg = defaultWeightKg
+	}
+
+	address, err := NormalizeAddress(req.Address)
+	if err != nil {
+		apierror.Respond(c, invalidAddress(err))
+		return
+	}
+	req.Address = address
+
+	rates, err := s.quoteRates(req)
+	if err != nil {
+		apierror.Respond(c, carrierError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rates": rates})
+}
+
+func (s *Service) handleGetCarrierTracking(c *gin.Context) {
+	shippingID := c.Param("id")
+
+	s.mu.RLock()
+	shipping, exists := s.shippings[shippingID]
+	s.mu.RUnlock()
+
+	if !exists {
+		apierror.Respond(c, apierror.NotFound("Shipping not found"))
+		return
+	}
+
+	carrier, err := s.carrier(shipping.Carrier)
+	if err != nil {
+		apierror.Respond(c, carrierError(err))
+		return
+	}
+
+	events, err := carrier.Track(shipping.TrackingNumber)
+	if err != nil {
+		apierror.Respond(c, carrierError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"carrier":         shipping.Carrier,
+		"tracking_number": shipping.TrackingNumber,
+		"events":          events,
+	})
+}
+
+// quoteRates collects rates from the requested carrier, or from every
+// configured carrier when none is given.
+func (s *Service) quoteRates(req models.ShippingQuoteRequest) ([]models.ShippingRate, error) {
+	carriers := s.carriers
+	if req.Carrier != "" {
+		carrier, err := s.carrier(req.Carrier)
+		if err != nil {
+			return nil, err
+		}
+		carriers = []Carrier{carrier}
+	}
+
+	rates := []models.ShippingRate{}
+	for _, carrier := range carriers {
+		carrierRates, err := carrier.QuoteRates(req)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, carrierRates...)
+	}
+
+	return rates, nil
+}
+
+// chooseRate resolves the carrier and rate a new shipment is booked with.
+func (s *Service) chooseRate(req models.StartShippingRequest) (Carrier, models.ShippingRate, error) {
+	rates, err := s.quoteRates(models.ShippingQuoteRequest{
+		Address:  req.Address,
+		WeightKg: req.WeightKg,
+		Carrier:  req.Carrier,
+	})
+	if err != nil {
+		return nil, models.ShippingRate{}, err
+	}
+
+	if req.Service != "" {
+		filtered := rates[:0]
+		for _, rate := range rates {
+			if strings.EqualFold(rate.Service, req.Service) {
+				filtered = append(filtered, rate)
+			}
+		}
+		rates = filtered
+	}
+
+	rate, err := selectRate(rates, req.Selection)
+	if err != nil {
+		return nil, models.ShippingRate{}, err
+	}
+
+	carrier, err := s.carrier(rate.Carrier)
+	if err != nil {
+		return nil, models.ShippingRate{}, err
+	}
+
+	return carrier, rate, nil
+}
+
+func (s *Service) carrier(code string) (Carrier, error) {
+	for _, carrier := range s.carriers {
+		if strings.EqualFold(carrier.Code(), code) {
+			return carrier, nil
+		}
+	}
+	return nil, ErrCarrierNotFound
+}
+
+// carrierError describes a failure to quote or book a carrier. Anything
+// other than an unknown carrier or no rates is the carrier's own failure.
+func carrierError(err error) *apierror.Error {
+	switch {
+	case errors.Is(err, ErrCarrierNotFound):
+		return apierror.InvalidRequest(err.Error())
+	case errors.Is(err, ErrNoRates):
+		return apierror.ValidationFailed(err.Error())
+	default:
+		return apierror.New(http.StatusBadGateway, apierror.CodeUpstreamFailed, err.Error())
+	}
+}
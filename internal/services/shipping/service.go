@@ -0,0 +1,384 @@
+package shipping
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/apierror"
+	"saga-order-system/internal/etag"
+	"saga-order-system/internal/health"
+	"saga-order-system/internal/logging"
+	"saga-order-system/internal/models"
+	"saga-order-system/internal/tracing"
+)
+
+type Service struct {
+	shippings map[string]models.Shipping
+	mu        sync.RWMutex
+	carriers  []Carrier
+	// orderServiceURL receives every shipment status change.
+	orderServiceURL string
+	client          *http.Client
+	// Untuk simulasi kegagalan pengiriman
+	failNextShipping bool
+}
+
+// NewService creates the shipping service. httpTimeout bounds the requests
+// it makes to the order service and to callback URLs.
+func NewService(orderServiceURL string, httpTimeout time.Duration, carriers []Carrier) *Service {
+	return &Service{
+		shippings:       make(map[string]models.Shipping),
+		carriers:        carriers,
+		orderServiceURL: orderServiceURL,
+		client: &http.Client{
+			Timeout: httpTimeout,
+		},
+		failNextShipping: false,
+	}
+}
+
+// Ready reports whether the service's store is usable.
+func (s *Service) Ready(ctx context.Context) error {
+	return health.Lockable(ctx, &s.mu)
+}
+
+func (s *Service) SetupRoutes(router *gin.Engine) {
+	router.POST("/addresses/validate", s.handleValidateAddress)
+	router.POST("/shipping-quotes", s.handleQuoteShipping)
+	router.POST("/start-shipping", s.handleStartShipping)
+	router.POST("/cancel-shipping", s.handleCancelShipping)
+	router.GET("/shippings/:id", s.handleGetShipping)
+	router.GET("/orders/:id/shippings", s.handleListOrderShippings)
+	router.POST("/shippings/:id/tracking-events", s.handleAddTrackingEvent)
+	router.GET("/shippings/:id/carrier-tracking", s.handleGetCarrierTracking)
+	router.POST("/set-fail-next-shipping", s.handleSetFailNextShipping)
+}
+
+func (s *Service) handleSetFailNextShipping(c *gin.Context) {
+	var req struct {
+		Fail bool `json:"fail"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	s.failNextShipping = req.Fail
+	s.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Set fail next shipping to %v", req.Fail)})
+}
+
+// StartShipping books a shipment with the requested carrier, or with the
+// one the request's selection picks from the available quotes, and reports
+// it to the order service.
+func (s *Service) StartShipping(ctx context.Context, req models.StartShippingRequest) (models.ShippingResponse, error) {
+	// Simulasi kegagalan pengiriman
+	s.mu.Lock()
+	failShipping := s.failNextShipping
+	s.failNextShipping = false
+	s.mu.Unlock()
+	if failShipping {
+		return models.ShippingResponse{}, apierror.Internal("Shipping failed")
+	}
+
+	if req.WeightKg == 0 {
+		req.WeightKg = defaultWeightKg
+	}
+
+	address, err := NormalizeAddress(req.Address)
+	if err != nil {
+		return models.ShippingResponse{}, invalidAddress(err)
+	}
+	req.Address = address
+
+	carrier, rate, err := s.chooseRate(req)
+	if err != nil {
+		return models.ShippingResponse{}, carrierError(err)
+	}
+
+	label, err := carrier.CreateLabel(LabelRequest{
+		OrderID:  req.OrderID,
+		Address:  req.Address,
+		WeightKg: req.WeightKg,
+		Service:  rate.Service,
+	})
+	if err != nil {
+		return models.ShippingResponse{}, carrierError(err)
+	}
+
+	shipping := models.NewShipping(req)
+	shipping.Carrier = label.Carrier
+	shipping.Service = label.Service
+	shipping.TrackingNumber = label.TrackingNumber
+	shipping.Cost = label.Cost
+	shipping.Track(models.TrackingEvent{
+		Status:      models.ShippingStatusLabelCreated,
+		Description: fmt.Sprintf("%s %s label created", label.Carrier, label.Service),
+	})
+
+	s.mu.Lock()
+	s.shippings[shipping.ID] = shipping
+	s.mu.Unlock()
+
+	s.notifyShipmentUpdate(ctx, shipping)
+	return shippingResponse(shipping), nil
+}
+
+func (s *Service) handleStartShipping(c *gin.Context) {
+	var req models.StartShippingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	shipping, err := s.StartShipping(c.Request.Context(), req)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	etag.Set(c, shipping.Version)
+	c.JSON(http.StatusCreated, shipping)
+}
+
+// CancelShipping cancels every active shipment of the order, or only the
+// one given by shipping_id, and returns how many it cancelled. Nothing is
+// cancelled if any of them has already been picked up, or if ifMatch does
+// not name the version of each of them.
+func (s *Service) CancelShipping(ctx context.Context, req models.CancelShippingRequest, ifMatch etag.Precondition) (int, error) {
+	s.mu.Lock()
+
+	var toCancel []models.Shipping
+	for _, shipping := range s.shippings {
+		if shipping.OrderID != req.OrderID || shipping.Status == models.ShippingStatusCancelled {
+			continue
+		}
+		// Without an explicit shipping_id only outbound shipments are cancelled.
+		if req.ShippingID != "" && shipping.ID != req.ShippingID ||
+			req.ShippingID == "" && shipping.Kind == models.ShipmentKindReturn {
+			continue
+		}
+		if !shipping.Status.CanTransitionTo(models.ShippingStatusCancelled) {
+			s.mu.Unlock()
+			return 0, apierror.Conflict(fmt.Sprintf("Shipping %s for order %s is %s and can no longer be cancelled", shipping.ID, req.OrderID, shipping.Status))
+		}
+		if err := ifMatch.Check(shipping.Version); err != nil {
+			s.mu.Unlock()
+			return 0, err
+		}
+		toCancel = append(toCancel, shipping)
+	}
+
+	for i, shipping := range toCancel {
+		if carrier, err := s.carrier(shipping.Carrier); err == nil {
+			if err := carrier.CancelLabel(shipping.TrackingNumber); err != nil {
+				logging.FromContext(ctx).Warn("Failed to cancel label",
+					"carrier", shipping.Carrier, "tracking_number", shipping.TrackingNumber, "error", err)
+			}
+		}
+		shipping.Track(models.TrackingEvent{
+			Status:      models.ShippingStatusCancelled,
+			Description: "Shipping cancelled",
+		})
+		s.shippings[shipping.ID] = shipping
+		toCancel[i] = shipping
+	}
+
+	s.mu.Unlock()
+
+	for _, shipping := range toCancel {
+		s.notifyShipmentUpdate(ctx, shipping)
+	}
+	return len(toCancel), nil
+}
+
+func (s *Service) handleCancelShipping(c *gin.Context) {
+	var req models.CancelShippingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	cancelled, err := s.CancelShipping(c.Request.Context(), req, etag.IfMatch(c))
+	if err != nil {
+		etag.Respond(c, err)
+		return
+	}
+
+	if cancelled == 0 {
+		// Jika tidak ditemukan, kita anggap berhasil karena mungkin belum dibuat
+		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("No shipping found for order %s", req.OrderID)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%d shipping(s) for order %s cancelled successfully", cancelled, req.OrderID)})
+}
+
+// ListOrderShippings returns every shipment of the order, oldest first.
+func (s *Service) ListOrderShippings(orderID string) []models.ShippingResponse {
+	s.mu.RLock()
+	shippings := []models.ShippingResponse{}
+	for _, shipping := range s.shippings {
+		if shipping.OrderID == orderID {
+			shippings = append(shippings, shippingResponse(shipping))
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(shippings, func(i, j int) bool {
+		return shippings[i].CreatedAt.Before(shippings[j].CreatedAt)
+	})
+	return shippings
+}
+
+func (s *Service) handleListOrderShippings(c *gin.Context) {
+	c.JSON(http.StatusOK, s.ListOrderShippings(c.Param("id")))
+}
+
+// handleAddTrackingEvent records a carrier scan against a shipment and reports
+// the new status to the order service.
+func (s *Service) handleAddTrackingEvent(c *gin.Context) {
+	shippingID := c.Param("id")
+
+	var req models.TrackingEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.InvalidRequest(err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	shipping, exists := s.shippings[shippingID]
+	if !exists {
+		s.mu.Unlock()
+		apierror.Respond(c, apierror.NotFound("Shipping not found"))
+		return
+	}
+	if err := etag.IfMatch(c).Check(shipping.Version); err != nil {
+		s.mu.Unlock()
+		etag.Respond(c, err)
+		return
+	}
+	if !shipping.Status.CanTransitionTo(req.Status) {
+		s.mu.Unlock()
+		apierror.Respond(c, apierror.Conflict(fmt.Sprintf("Cannot move shipping from %s to %s", shipping.Status, req.Status)))
+		return
+	}
+
+	shipping.Track(models.TrackingEvent{
+		Status:      req.Status,
+		Location:    req.Location,
+		Description: req.Description,
+		OccurredAt:  req.OccurredAt,
+	})
+	s.shippings[shippingID] = shipping
+	s.mu.Unlock()
+
+	s.notifyShipmentUpdate(c.Request.Context(), shipping)
+
+	etag.Set(c, shipping.Version)
+	c.JSON(http.StatusOK, shippingResponse(shipping))
+}
+
+// notifyShipmentUpdate reports a shipment's status to the order service so
+// it can derive the order's fulfillment status. Return shipments instead
+// notify their callback URL once they reach the warehouse. Failures are only
+// logged; the next update carries the full state again.
+func (s *Service) notifyShipmentUpdate(ctx context.Context, shipping models.Shipping) {
+	if shipping.Kind == models.ShipmentKindReturn {
+		if shipping.Status == models.ShippingStatusDelivered && shipping.CallbackURL != "" {
+			s.notifyReturnReceived(ctx, shipping)
+		}
+		return
+	}
+
+	update := models.ShipmentUpdate{
+		ShippingID: shipping.ID,
+		Status:     shipping.Status,
+		Items:      shipping.Items,
+	}
+	if err := s.notify(ctx, s.orderServiceURL+"/orders/"+shipping.OrderID+"/shipment-updates", update); err != nil {
+		logging.FromContext(ctx).Warn("Failed to send shipment update", "shipping_id", shipping.ID, "error", err)
+	}
+}
+
+func (s *Service) notifyReturnReceived(ctx context.Context, shipping models.Shipping) {
+	receipt := map[string]interface{}{
+		"shipping_id": shipping.ID,
+		"status":      shipping.Status,
+	}
+	if err := s.notify(ctx, shipping.CallbackURL, receipt); err != nil {
+		logging.FromContext(ctx).Warn("Failed to send return receipt", "shipping_id", shipping.ID, "error", err)
+	}
+}
+
+// notify posts body to url, passing on the correlation IDs of the request
+// being handled.
+func (s *Service) notify(ctx context.Context, url string, body interface{}) error {
+	reqJSON, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(reqJSON))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	logging.Propagate(ctx, req.Header)
+	tracing.Propagate(ctx, req.Header)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status code %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *Service) handleGetShipping(c *gin.Context) {
+	shippingID := c.Param("id")
+
+	s.mu.RLock()
+	shipping, exists := s.shippings[shippingID]
+	s.mu.RUnlock()
+
+	if !exists {
+		apierror.Respond(c, apierror.NotFound("Shipping not found"))
+		return
+	}
+
+	etag.Set(c, shipping.Version)
+	c.JSON(http.StatusOK, shippingResponse(shipping))
+}
+
+func shippingResponse(shipping models.Shipping) models.ShippingResponse {
+	return models.ShippingResponse{
+		ID:             shipping.ID,
+		OrderID:        shipping.OrderID,
+		Kind:           shipping.Kind,
+		Items:          shipping.Items,
+		Address:        shipping.Address,
+		WeightKg:       shipping.WeightKg,
+		Carrier:        shipping.Carrier,
+		Service:        shipping.Service,
+		TrackingNumber: shipping.TrackingNumber,
+		Cost:           shipping.Cost,
+		Status:         shipping.Status,
+		Events:         shipping.Events,
+		Version:        shipping.Version,
+		CreatedAt:      shipping.CreatedAt,
+	}
+}
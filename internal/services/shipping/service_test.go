@@ -0,0 +1,247 @@
+package shipping
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/models"
+)
+
+// recorder stands in for the order service and callback URLs, keeping the
+// path and body of every request it receives.
+type recorder struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+type recordedRequest struct {
+	path string
+	body map[string]interface{}
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body map[string]interface{}
+	json.NewDecoder(req.Body).Decode(&body)
+	r.mu.Lock()
+	r.requests = append(r.requests, recordedRequest{path: req.URL.Path, body: body})
+	r.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *recorder) received() []recordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]recordedRequest(nil), r.requests...)
+}
+
+func newTestRouter(t *testing.T) (*gin.Engine, *recorder, string) {
+	t.Helper()
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	t.Cleanup(server.Close)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewService(server.URL, time.Second, DefaultCarriers()).SetupRoutes(router)
+	return router, rec, server.URL
+}
+
+func request(t *testing.T, router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func startShipping(t *testing.T, router *gin.Engine, req models.StartShippingRequest) models.ShippingResponse {
+	t.Helper()
+	if req.Address.Country == "" {
+		req.Address = models.Address{Recipient: "A", Lines: []string{"1 Jalan"}, City: "Jakarta", PostalCode: "10110", Country: "ID"}
+	}
+	w := request(t, router, http.MethodPost, "/start-shipping", req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("start status = %d: %s", w.Code, w.Body)
+	}
+	var shipping models.ShippingResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &shipping); err != nil {
+		t.Fatal(err)
+	}
+	return shipping
+}
+
+func TestTrackingEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []models.ShippingStatus
+		wantCode int
+	}{
+		{
+			name: "delivered",
+			statuses: []models.ShippingStatus{
+				models.ShippingStatusPickedUp,
+				models.ShippingStatusInTransit,
+				models.ShippingStatusInTransit,
+				models.ShippingStatusOutForDelivery,
+				models.ShippingStatusDelivered,
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "failed delivery attempt",
+			statuses: []models.ShippingStatus{
+				models.ShippingStatusPickedUp,
+				models.ShippingStatusInTransit,
+				models.ShippingStatusOutForDelivery,
+				models.ShippingStatusInTransit,
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "delivered before pickup",
+			statuses: []models.ShippingStatus{models.ShippingStatusDelivered},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "cancelled after pickup",
+			statuses: []models.ShippingStatus{
+				models.ShippingStatusPickedUp,
+				models.ShippingStatusCancelled,
+			},
+			wantCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, rec, _ := newTestRouter(t)
+			shipping := startShipping(t, router, models.StartShippingRequest{OrderID: "o1"})
+
+			var w *httptest.ResponseRecorder
+			for _, status := range tt.statuses {
+				w = request(t, router, http.MethodPost, "/shippings/"+shipping.ID+"/tracking-events",
+					models.TrackingEventRequest{Status: status})
+				if w.Code != http.StatusOK {
+					break
+				}
+			}
+			if w.Code != tt.wantCode {
+				t.Fatalf("last event status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var tracked models.ShippingResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &tracked); err != nil {
+				t.Fatal(err)
+			}
+			want := tt.statuses[len(tt.statuses)-1]
+			if tracked.Status != want || len(tracked.Events) != len(tt.statuses)+1 {
+				t.Errorf("shipping %s with %d events, want %s with %d", tracked.Status, len(tracked.Events), want, len(tt.statuses)+1)
+			}
+
+			// Every status change, including the label, reaches the order
+			// service.
+			updates := rec.received()
+			if len(updates) != len(tt.statuses)+1 {
+				t.Fatalf("order service received %d updates, want %d", len(updates), len(tt.statuses)+1)
+			}
+			last := updates[len(updates)-1]
+			if last.path != "/orders/o1/shipment-updates" || last.body["status"] != string(want) {
+				t.Errorf("last update %s %v", last.path, last.body)
+			}
+		})
+	}
+}
+
+func TestReturnShipmentCallback(t *testing.T) {
+	router, rec, url := newTestRouter(t)
+	shipping := startShipping(t, router, models.StartShippingRequest{
+		OrderID:     "o1",
+		Kind:        models.ShipmentKindReturn,
+		CallbackURL: url + "/sagas/s1/return-received",
+	})
+
+	for _, status := range []models.ShippingStatus{
+		models.ShippingStatusPickedUp,
+		models.ShippingStatusInTransit,
+		models.ShippingStatusOutForDelivery,
+		models.ShippingStatusDelivered,
+	} {
+		if w := request(t, router, http.MethodPost, "/shippings/"+shipping.ID+"/tracking-events",
+			models.TrackingEventRequest{Status: status}); w.Code != http.StatusOK {
+			t.Fatalf("%s status = %d: %s", status, w.Code, w.Body)
+		}
+	}
+
+	// Return shipments leave the order's fulfillment alone and only report
+	// their delivery to the callback.
+	received := rec.received()
+	if len(received) != 1 {
+		t.Fatalf("received %d requests, want 1", len(received))
+	}
+	if received[0].path != "/sagas/s1/return-received" || received[0].body["shipping_id"] != shipping.ID {
+		t.Errorf("callback %s %v", received[0].path, received[0].body)
+	}
+}
+
+func TestCancelShippingAfterPickup(t *testing.T) {
+	router, _, _ := newTestRouter(t)
+	shipping := startShipping(t, router, models.StartShippingRequest{OrderID: "o1"})
+
+	w := request(t, router, http.MethodPost, "/shippings/"+shipping.ID+"/tracking-events",
+		models.TrackingEventRequest{Status: models.ShippingStatusPickedUp})
+	if w.Code != http.StatusOK {
+		t.Fatalf("pickup status = %d: %s", w.Code, w.Body)
+	}
+
+	w = request(t, router, http.MethodPost, "/cancel-shipping", models.CancelShippingRequest{OrderID: "o1"})
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "can no longer be cancelled") {
+		t.Fatalf("cancel status = %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestSplitShipments(t *testing.T) {
+	router, _, _ := newTestRouter(t)
+	first := startShipping(t, router, models.StartShippingRequest{
+		OrderID: "o1",
+		Items:   []models.ShipmentItem{{ProductID: "p1", Quantity: 2}},
+	})
+	second := startShipping(t, router, models.StartShippingRequest{
+		OrderID: "o1",
+		Items:   []models.ShipmentItem{{ProductID: "p2", Quantity: 1}},
+	})
+
+	w := request(t, router, http.MethodPost, "/cancel-shipping", models.CancelShippingRequest{OrderID: "o1", ShippingID: second.ID})
+	if w.Code != http.StatusOK {
+		t.Fatalf("cancel status = %d: %s", w.Code, w.Body)
+	}
+
+	w = request(t, router, http.MethodGet, "/orders/o1/shippings", nil)
+	var shippings []models.ShippingResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &shippings); err != nil {
+		t.Fatal(err)
+	}
+	if len(shippings) != 2 || shippings[0].ID != first.ID || shippings[1].ID != second.ID {
+		t.Fatalf("shippings %+v, want %s then %s", shippings, first.ID, second.ID)
+	}
+	if shippings[0].Status != models.ShippingStatusLabelCreated || shippings[1].Status != models.ShippingStatusCancelled {
+		t.Errorf("statuses %s and %s, want only the second cancelled", shippings[0].Status, shippings[1].Status)
+	}
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// Exporter receives every finished span.
+type Exporter interface {
+	Export(span *Span) error
+}
+
+// JSONExporter writes each span as one line of JSON, so traces can be
+// inspected offline with any JSON tooling.
+type JSONExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{w: w}
+}
+
+func (e *JSONExporter) Export(span *Span) error {
+	span.mu.Lock()
+	line, err := json.Marshal(span)
+	span.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(append(line, '\n'))
+	return err
+}
+
+// NewExporter picks the exporter for output: nothing for "", standard
+// output for "stdout", otherwise a JSON lines file appended to at that path.
+func NewExporter(output string) (Exporter, error) {
+	switch output {
+	case "":
+		return nil, nil
+	case "stdout":
+		return NewJSONExporter(os.Stdout), nil
+	}
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONExporter(f), nil
+}
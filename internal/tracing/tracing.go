@@ -0,0 +1,213 @@
+// Package tracing records spans, propagates them between binaries with the
+// W3C traceparent header and hands finished spans to an Exporter.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"saga-order-system/internal/logging"
+)
+
+const traceparentHeader = "traceparent"
+
+// SpanContext identifies a span within its trace.
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+}
+
+func (sc SpanContext) IsValid() bool {
+	return len(sc.TraceID) == 32 && len(sc.SpanID) == 16
+}
+
+// Traceparent renders the span context as a W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-01", sc.TraceID, sc.SpanID)
+}
+
+// ParseTraceparent reads a W3C traceparent header value.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return SpanContext{}, false
+	}
+	sc := SpanContext{TraceID: parts[1], SpanID: parts[2]}
+	if !sc.IsValid() || !isHex(sc.TraceID) || !isHex(sc.SpanID) ||
+		sc.TraceID == strings.Repeat("0", 32) || sc.SpanID == strings.Repeat("0", 16) {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+// Span is one timed operation. Finished spans are exported as JSON.
+type Span struct {
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	ParentID   string            `json:"parent_id,omitempty"`
+	Name       string            `json:"name"`
+	Service    string            `json:"service"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	DurationMs float64           `json:"duration_ms"`
+	Status     string            `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	mu     sync.Mutex
+	tracer *Tracer
+	ended  bool
+}
+
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return SpanContext{TraceID: s.TraceID, SpanID: s.SpanID}
+}
+
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]string)
+	}
+	s.Attributes[key] = value
+	s.mu.Unlock()
+}
+
+// Finish ends the span, marking it failed when err is not nil, and exports
+// it. Only the first call has any effect.
+func (s *Span) Finish(err error) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	s.DurationMs = float64(s.End.Sub(s.Start).Microseconds()) / 1000
+	s.Status = "ok"
+	if err != nil {
+		s.Status = "error"
+		s.Error = err.Error()
+	}
+	s.mu.Unlock()
+
+	s.tracer.export(s)
+}
+
+// Tracer starts spans for one service.
+type Tracer struct {
+	service  string
+	exporter Exporter
+}
+
+// NewTracer creates a tracer for service. Spans are still created and
+// propagated when exporter is nil, but not recorded anywhere.
+func NewTracer(service string, exporter Exporter) *Tracer {
+	return &Tracer{service: service, exporter: exporter}
+}
+
+// Start begins a span as a child of the span in ctx, or of a new trace.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	span := t.StartWithParent(parent, name)
+	return ContextWithSpan(ctx, span), span
+}
+
+// StartWithParent begins a span as a child of parent, or of a new trace when
+// parent is not valid.
+func (t *Tracer) StartWithParent(parent SpanContext, name string) *Span {
+	span := &Span{
+		SpanID:  randomHex(8),
+		Name:    name,
+		Service: t.service,
+		Start:   time.Now(),
+		tracer:  t,
+	}
+	if parent.IsValid() {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+	} else {
+		span.TraceID = randomHex(16)
+	}
+	return span
+}
+
+func (t *Tracer) export(span *Span) {
+	if t.exporter == nil {
+		return
+	}
+	if err := t.exporter.Export(span); err != nil {
+		// Tracing must never break the request being traced.
+		logging.Default().Error("Failed to export span", "trace_id", span.TraceID, "span_id", span.SpanID, "error", err)
+	}
+}
+
+type spanContextKey struct{}
+
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span.Context())
+}
+
+// Propagate sets the traceparent header of an outgoing request made within
+// the span in ctx.
+func Propagate(ctx context.Context, header http.Header) {
+	if sc, _ := ctx.Value(spanContextKey{}).(SpanContext); sc.IsValid() {
+		header.Set(traceparentHeader, sc.Traceparent())
+	}
+}
+
+// Middleware starts a server span for every request, continuing the trace
+// of an incoming traceparent header, and makes it the request context's span.
+func Middleware(tracer *Tracer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent, _ := ParseTraceparent(c.GetHeader(traceparentHeader))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		span := tracer.StartWithParent(parent, c.Request.Method+" "+route)
+		c.Request = c.Request.WithContext(ContextWithSpan(c.Request.Context(), span))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.method", c.Request.Method)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("http.status_code", fmt.Sprint(status))
+		var err error
+		if status >= http.StatusInternalServerError {
+			err = fmt.Errorf("status code %d", status)
+		}
+		span.Finish(err)
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil && strings.ToLower(s) == s
+}
@@ -0,0 +1,188 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  SpanContext
+		ok    bool
+	}{
+		{
+			name:  "valid",
+			value: "00-" + testTraceID + "-" + testSpanID + "-01",
+			want:  SpanContext{TraceID: testTraceID, SpanID: testSpanID},
+			ok:    true,
+		},
+		{
+			name:  "surrounding spaces",
+			value: " 00-" + testTraceID + "-" + testSpanID + "-00 ",
+			want:  SpanContext{TraceID: testTraceID, SpanID: testSpanID},
+			ok:    true,
+		},
+		{name: "empty", value: ""},
+		{name: "unknown version", value: "01-" + testTraceID + "-" + testSpanID + "-01"},
+		{name: "missing flags", value: "00-" + testTraceID + "-" + testSpanID},
+		{name: "short trace ID", value: "00-" + testTraceID[:30] + "-" + testSpanID + "-01"},
+		{name: "upper case", value: "00-" + strings.ToUpper(testTraceID) + "-" + testSpanID + "-01"},
+		{name: "not hex", value: "00-" + strings.Repeat("z", 32) + "-" + testSpanID + "-01"},
+		{name: "zero trace ID", value: "00-" + strings.Repeat("0", 32) + "-" + testSpanID + "-01"},
+		{name: "zero span ID", value: "00-" + testTraceID + "-" + strings.Repeat("0", 16) + "-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseTraceparent(tt.value)
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("ParseTraceparent(%q) = %+v, %v, want %+v, %v", tt.value, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestPropagate(t *testing.T) {
+	tracer := NewTracer("test", nil)
+
+	header := http.Header{}
+	Propagate(context.Background(), header)
+	if got := header.Get(traceparentHeader); got != "" {
+		t.Fatalf("traceparent %q set without a span", got)
+	}
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	ctx, child := tracer.Start(ctx, "child")
+	if child.TraceID != parent.TraceID || child.ParentID != parent.SpanID {
+		t.Fatalf("child %+v is not a child of %+v", child.Context(), parent.Context())
+	}
+
+	Propagate(ctx, header)
+	sc, ok := ParseTraceparent(header.Get(traceparentHeader))
+	if !ok || sc != child.Context() {
+		t.Fatalf("propagated %q, want the child span %+v", header.Get(traceparentHeader), child.Context())
+	}
+}
+
+// recordingExporter keeps the spans exported to it.
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (e *recordingExporter) Export(span *Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		status      int
+		wantTrace   string
+		wantParent  string
+		wantStatus  string
+	}{
+		{
+			name:        "continues the incoming trace",
+			traceparent: "00-" + testTraceID + "-" + testSpanID + "-01",
+			status:      http.StatusOK,
+			wantTrace:   testTraceID,
+			wantParent:  testSpanID,
+			wantStatus:  "ok",
+		},
+		{
+			name:       "starts a trace without a header",
+			status:     http.StatusOK,
+			wantStatus: "ok",
+		},
+		{
+			name:        "server errors fail the span",
+			traceparent: "garbage",
+			status:      http.StatusBadGateway,
+			wantStatus:  "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := &recordingExporter{}
+			tracer := NewTracer("test", exporter)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(Middleware(tracer))
+			var outgoing string
+			router.GET("/orders/:id", func(c *gin.Context) {
+				header := http.Header{}
+				Propagate(c.Request.Context(), header)
+				outgoing = header.Get(traceparentHeader)
+				c.Status(tt.status)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/orders/o1", nil)
+			if tt.traceparent != "" {
+				req.Header.Set(traceparentHeader, tt.traceparent)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if len(exporter.spans) != 1 {
+				t.Fatalf("exported %d spans, want 1", len(exporter.spans))
+			}
+			span := exporter.spans[0]
+			if tt.wantTrace != "" && span.TraceID != tt.wantTrace || span.ParentID != tt.wantParent {
+				t.Errorf("span trace %s parent %q, want %s parent %q", span.TraceID, span.ParentID, tt.wantTrace, tt.wantParent)
+			}
+			if span.Name != "GET /orders/:id" || span.Status != tt.wantStatus {
+				t.Errorf("span %q status %s, want status %s", span.Name, span.Status, tt.wantStatus)
+			}
+			if outgoing != span.Context().Traceparent() {
+				t.Errorf("handler propagated %q, want the server span %q", outgoing, span.Context().Traceparent())
+			}
+		})
+	}
+}
+
+func TestSpanFinishOnce(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer("test", NewJSONExporter(&buf))
+	_, span := tracer.Start(context.Background(), "work")
+
+	span.Finish(errors.New("failed"))
+	span.Finish(nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("exported %d lines, want 1", len(lines))
+	}
+	var exported struct {
+		Service string `json:"service"`
+		Status  string `json:"status"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &exported); err != nil {
+		t.Fatal(err)
+	}
+	if exported.Status != "error" || exported.Error != "failed" || exported.Service != "test" {
+		t.Errorf("exported %+v", exported)
+	}
+}